@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/h3poteto/ecs-task/pkg/task"
+	"github.com/spf13/cobra"
+)
+
+// completionTimeout limits AWS API calls for shell completion, because shells wait for them.
+const completionTimeout = 10 * time.Second
+
+// registerCompletions registers dynamic completion of flags which exist in the command.
+// Candidates are read from AWS with the active profile and region.
+func registerCompletions(cmd *cobra.Command) {
+	completions := map[string]func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective){
+		"cluster":         completeClusters,
+		"task-definition": completeFamilies,
+		"family":          completeFamilies,
+		"container":       completeContainers,
+	}
+	for name, f := range completions {
+		if cmd.Flags().Lookup(name) != nil {
+			cmd.RegisterFlagCompletionFunc(name, f)
+		}
+	}
+}
+
+// completionClient returns an ECS client for completion.
+func completionClient() (*ecs.Client, error) {
+	profile, region, _ := generalConfig()
+	cfg, err := task.NewConfig(profile, region, configOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	return ecs.NewFromConfig(cfg), nil
+}
+
+// completeClusters completes cluster names. The cluster flag accepts comma-separated names, so the last name is completed.
+func completeClusters(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	client, err := completionClient()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+	names, err := task.ClusterNames(ctx, client)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	selected := ""
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		selected = toComplete[:i+1]
+	}
+	candidates := []string{}
+	for _, name := range names {
+		if strings.HasPrefix(selected+name, toComplete) {
+			candidates = append(candidates, selected+name)
+		}
+	}
+	return candidates, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeFamilies completes task definition families.
+func completeFamilies(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	client, err := completionClient()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+	families, err := task.NewTaskDefinition(client).ListFamilies(ctx, toComplete)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return families, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeContainers completes container names in the task definition which is provided by task-definition flag.
+func completeContainers(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	taskDefinitionName, err := cmd.Flags().GetString("task-definition")
+	if err != nil || taskDefinitionName == "" {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	client, err := completionClient()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+	taskDefinition := task.NewTaskDefinition(client)
+	taskDef, err := taskDefinition.DescribeTaskDefinition(ctx, taskDefinitionName)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return taskDefinition.ContainerNames(taskDef), cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/h3poteto/ecs-task/pkg/compose"
+	"github.com/h3poteto/ecs-task/pkg/task"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+type composeTask struct {
+	runTask
+	file             string
+	service          string
+	family           string
+	executionRoleArn string
+	taskRoleArn      string
+	logGroup         string
+	hostEnv          bool
+}
+
+func composeCmd() *cobra.Command {
+	c := &composeTask{}
+	cmd := &cobra.Command{
+		Use:   "compose",
+		Short: "Register a task definition from a docker-compose service, and run it on ECS",
+		Run:   c.run,
+	}
+
+	flags := cmd.Flags()
+	c.runTask.addFlags(flags)
+	flags.Lookup("command").Usage = "Command which you want to run (default is the command of the service)"
+	flags.StringVar(&c.file, "file", "docker-compose.yml", "Path to docker-compose file")
+	flags.StringVar(&c.service, "service", "", "Name of the service in docker-compose file")
+	flags.StringVar(&c.family, "family", "", "Family of the task definition (default is the service name)")
+	flags.StringVar(&c.executionRoleArn, "execution-role-arn", "", "Task execution role ARN. It is required to use awslogs on Fargate.")
+	flags.StringVar(&c.taskRoleArn, "task-role-arn", "", "Task role ARN")
+	flags.StringVar(&c.logGroup, "log-group", "", "Log group of awslogs, if the service does not use awslogs driver (default is /ecs-task/<family>, and the execution role creates it)")
+	flags.BoolVar(&c.hostEnv, "host-env", false, "Read values of environment variables which are omitted in the service from the current environment. Note that the values are stored in the task definition.")
+	registerCompletions(cmd)
+
+	return cmd
+}
+
+func (c *composeTask) run(cmd *cobra.Command, args []string) {
+	profile, region, verbose := generalConfig()
+	if !verbose {
+		log.SetLevel(log.WarnLevel)
+	}
+	if c.service == "" {
+		log.Fatal("Service name is required")
+	}
+	project, err := compose.Load(c.file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	cfg, err := task.NewConfig(profile, region, configOptions()...)
+	if err != nil {
+		log.Fatal(errors.Wrap(err, "Failed to create AWS Session"))
+	}
+	input, err := project.TaskDefinition(c.service, &compose.TaskDefinitionOptions{
+		Family:           c.family,
+		Fargate:          c.fargate,
+		Cpu:              c.taskSizeCpu,
+		Memory:           c.taskSizeMemory,
+		ExecutionRoleArn: c.executionRoleArn,
+		TaskRoleArn:      c.taskRoleArn,
+		LogGroup:         c.logGroup,
+		LogRegion:        cfg.Region,
+		HostEnvironment:  c.hostEnv,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Validate the command before registering, so that an invalid run does not leave an unused revision.
+	if c.command == "" && !c.script.enabled() {
+		c.command = shellJoin(project.Services[c.service].Command)
+		if c.command == "" {
+			log.Fatal("Command is required, because the service does not have command")
+		}
+	}
+	if c.command != "" && c.script.enabled() {
+		log.Fatal("Provide either command or script")
+	}
+	taskDef, err := task.NewTaskDefinition(ecs.NewFromConfig(cfg)).RegisterTaskDefinition(context.Background(), input)
+	if err != nil {
+		log.Fatal(errors.Wrap(err, "Failed to register task definition"))
+	}
+	log.Infof("Registered task definition: %s", *taskDef.TaskDefinitionArn)
+
+	c.taskDefinition = *taskDef.TaskDefinitionArn
+	c.container = c.service
+	c.runTask.run(cmd, args)
+}
+
+// shellJoin quotes and joins arguments, so that they are parsed to the same arguments as a shell does.
+func shellJoin(args []string) string {
+	quoted := []string{}
+	for _, arg := range args {
+		quoted = append(quoted, "'"+strings.ReplaceAll(arg, "'", `'\''`)+"'")
+	}
+	return strings.Join(quoted, " ")
+}
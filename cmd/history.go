@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/h3poteto/ecs-task/pkg/history"
+	"github.com/h3poteto/ecs-task/pkg/task"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type listHistory struct {
+	family  string
+	cluster string
+	user    string
+	since   string
+	until   string
+	limit   int
+	history historyStore
+}
+
+func historyCmd() *cobra.Command {
+	h := &listHistory{}
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "List past runs",
+		Long:  "List past runs from the history store. If history-table or history-bucket is not provided, recently stopped tasks in the cluster are listed instead.",
+		RunE:  h.run,
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&h.family, "family", "d", "", "Family of task definition")
+	flags.StringVarP(&h.cluster, "cluster", "c", "", "Name of ECS Cluster. This param is necessary, if there is no history store.")
+	flags.StringVarP(&h.user, "user", "u", "", "Initiator of runs. Runs whose initiator contains this are listed.")
+	flags.StringVar(&h.since, "since", "", "List runs after this time. Provide RFC3339 time (2006-01-02T15:04:05Z) or duration before now (24h).")
+	flags.StringVar(&h.until, "until", "", "List runs before this time. Provide RFC3339 time (2006-01-02T15:04:05Z) or duration before now (24h).")
+	flags.IntVar(&h.limit, "limit", 50, "Maximum number of runs. If you set 0, all runs are listed.")
+	h.history.addFlags(flags)
+	registerCompletions(cmd)
+
+	return cmd
+}
+
+func (h *listHistory) run(cmd *cobra.Command, args []string) error {
+	profile, region, verbose := generalConfig()
+	if !verbose {
+		log.SetLevel(log.WarnLevel)
+	}
+	filter := &history.Filter{
+		Family:    h.family,
+		Cluster:   h.cluster,
+		Initiator: h.user,
+		Limit:     h.limit,
+	}
+	var err error
+	if filter.Since, err = parseTime(h.since); err != nil {
+		return err
+	}
+	if filter.Until, err = parseTime(h.until); err != nil {
+		return err
+	}
+
+	cfg, err := task.NewConfig(profile, region, configOptions()...)
+	if err != nil {
+		return errors.Wrap(err, "Failed to create AWS Session")
+	}
+	ctx := context.Background()
+	var records []*history.Record
+	if h.history.enabled() {
+		store, err := h.history.store(cfg)
+		if err != nil {
+			return err
+		}
+		records, err = store.List(ctx, filter)
+		if err != nil {
+			return err
+		}
+	} else {
+		if h.cluster == "" {
+			return errors.New("Cluster name is required, if there is no history store")
+		}
+		log.Info("History store is not provided; listing recently stopped tasks")
+		records, err = history.ListStoppedTasks(ctx, ecs.NewFromConfig(cfg), h.cluster, filter)
+		if err != nil {
+			return err
+		}
+	}
+	return printOutput(historyRecords(records))
+}
+
+// parseTime parses RFC3339 time or duration before now. Empty string is parsed as zero time.
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, errors.Errorf("Invalid time: %s", value)
+	}
+	return t, nil
+}
+
+// historyRecords is an output of history command.
+type historyRecords []*history.Record
+
+func (h historyRecords) text() string {
+	lines := []string{}
+	for _, r := range h {
+		status := "succeeded"
+		if r.Running {
+			status = "running"
+		} else if !r.Result.Success {
+			status = "failed"
+		}
+		lines = append(lines, fmt.Sprintf("%s %s/%s %s %s by %s (exit code: %s, duration: %s): %s",
+			r.RecordedAt.Local().Format(time.RFC3339), r.Result.Cluster, r.Family, r.Result.TaskID, status, r.Initiator,
+			exitCode(r.Result.ExitCode), r.Result.Duration.Round(time.Second), strings.Join(r.Result.Command, " ")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (h historyRecords) header() []string {
+	return []string{"TIME", "CLUSTER", "FAMILY", "TASK ID", "INITIATOR", "EXIT CODE", "DURATION", "SUCCESS", "COMMAND"}
+}
+
+func (h historyRecords) rows() [][]string {
+	rows := [][]string{}
+	for _, r := range h {
+		rows = append(rows, []string{
+			r.RecordedAt.Local().Format(time.RFC3339), r.Result.Cluster, r.Family, r.Result.TaskID, r.Initiator,
+			exitCode(r.Result.ExitCode), r.Result.Duration.Round(time.Second).String(), strconv.FormatBool(r.Result.Success),
+			strings.Join(r.Result.Command, " "),
+		})
+	}
+	return rows
+}
+
+// historyStore has flags to specify the history store.
+type historyStore struct {
+	table  string
+	bucket string
+	prefix string
+}
+
+func (h *historyStore) addFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&h.table, "history-table", "", "DynamoDB table name to record history of runs. The table is created if it does not exist.")
+	flags.StringVar(&h.bucket, "history-bucket", "", "S3 bucket name to record history of runs. The bucket is created if it does not exist.")
+	flags.StringVar(&h.prefix, "history-prefix", "ecs-task/history/", "Prefix of S3 objects to record history of runs.")
+}
+
+// enabled returns whether the history store is specified.
+func (h *historyStore) enabled() bool {
+	return h.table != "" || h.bucket != ""
+}
+
+// store returns the history store which is specified by flags.
+func (h *historyStore) store(cfg aws.Config) (history.Store, error) {
+	if h.table != "" && h.bucket != "" {
+		return nil, errors.New("Provide either history-table or history-bucket")
+	}
+	if h.table != "" {
+		return history.NewDynamoDBStore(dynamodb.NewFromConfig(cfg), h.table), nil
+	}
+	if h.bucket != "" {
+		return history.NewS3Store(s3.NewFromConfig(cfg), h.bucket, h.prefix, cfg.Region), nil
+	}
+	return nil, errors.New("History store is not specified")
+}
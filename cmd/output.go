@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+const (
+	outputText  = "text"
+	outputJSON  = "json"
+	outputTable = "table"
+)
+
+// texter is implemented by outputs which can be rendered as human readable text.
+type texter interface {
+	text() string
+}
+
+// tabular is implemented by outputs which can be rendered as a table.
+type tabular interface {
+	header() []string
+	rows() [][]string
+}
+
+// outputFormat returns the output format which is provided by the output flag.
+func outputFormat() (string, error) {
+	format := viper.GetString("output")
+	switch format {
+	case "", outputText:
+		return outputText, nil
+	case outputJSON, outputTable:
+		return format, nil
+	default:
+		return "", errors.Errorf("Unknown output format: %s", format)
+	}
+}
+
+// printOutput renders the value to stdout according to the output flag.
+func printOutput(v interface{}) error {
+	format, err := outputFormat()
+	if err != nil {
+		return err
+	}
+	return render(os.Stdout, format, v)
+}
+
+// render writes the value to the writer with the format.
+// JSON is available for every value, but text and table require the value to implement texter and tabular.
+func render(w io.Writer, format string, v interface{}) error {
+	switch format {
+	case outputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case outputTable:
+		t, ok := v.(tabular)
+		if !ok {
+			return errors.Errorf("%s output is not supported", format)
+		}
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(t.header(), "\t"))
+		for _, row := range t.rows() {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	default:
+		t, ok := v.(texter)
+		if !ok {
+			return errors.Errorf("%s output is not supported", format)
+		}
+		text := t.text()
+		if text == "" {
+			return nil
+		}
+		_, err := fmt.Fprintln(w, text)
+		return err
+	}
+}
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/h3poteto/ecs-task/pkg/task"
+	"github.com/pkg/errors"
+	"golang.org/x/term"
+)
+
+// maxPickerItems is the number of items which are shown at once.
+const maxPickerItems = 20
+
+// picker asks users to select an item with fuzzy search on the terminal.
+type picker struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func newPicker() *picker {
+	return &picker{
+		in:  bufio.NewReader(os.Stdin),
+		out: os.Stderr,
+	}
+}
+
+// interactive returns whether users can select parameters on the terminal.
+func interactive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// pick shows items, and returns the selected item.
+// Users type a query to filter items, or the number of the item to select it.
+func (p *picker) pick(label string, items []string) (string, error) {
+	if len(items) == 0 {
+		return "", errors.Errorf("There are no %ss", label)
+	}
+	if len(items) == 1 {
+		fmt.Fprintf(p.out, "Selected %s: %s\n", label, items[0])
+		return items[0], nil
+	}
+	candidates := items
+	for {
+		for i, item := range candidates {
+			if i >= maxPickerItems {
+				fmt.Fprintf(p.out, "  ... and %d more\n", len(candidates)-maxPickerItems)
+				break
+			}
+			fmt.Fprintf(p.out, "  %d) %s\n", i+1, item)
+		}
+		fmt.Fprintf(p.out, "Select %s (type to filter, or number): ", label)
+		line, err := p.in.ReadString('\n')
+		if err != nil && line == "" {
+			return "", errors.Wrap(err, "Failed to read input")
+		}
+		line = strings.TrimSpace(line)
+
+		if n, err := strconv.Atoi(line); err == nil && n >= 1 && n <= len(candidates) && n <= maxPickerItems {
+			return candidates[n-1], nil
+		}
+		if line == "" {
+			if len(candidates) == 1 {
+				return candidates[0], nil
+			}
+			candidates = items
+			continue
+		}
+		matched := fuzzyFilter(items, line)
+		if len(matched) == 0 {
+			fmt.Fprintf(p.out, "No %s matches %q\n", label, line)
+			candidates = items
+			continue
+		}
+		if len(matched) == 1 {
+			fmt.Fprintf(p.out, "Selected %s: %s\n", label, matched[0])
+			return matched[0], nil
+		}
+		candidates = matched
+	}
+}
+
+// input asks users to type a value.
+func (p *picker) input(label string) (string, error) {
+	fmt.Fprintf(p.out, "%s: ", label)
+	line, err := p.in.ReadString('\n')
+	if err != nil && line == "" {
+		return "", errors.Wrap(err, "Failed to read input")
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// fuzzyFilter returns items which contain characters of the query in order, ignoring case.
+// Items which contain the query as it is come first, and shorter items come first.
+func fuzzyFilter(items []string, query string) []string {
+	query = strings.ToLower(query)
+	matched := []string{}
+	for _, item := range items {
+		if fuzzyMatch(strings.ToLower(item), query) {
+			matched = append(matched, item)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		ci := strings.Contains(strings.ToLower(matched[i]), query)
+		cj := strings.Contains(strings.ToLower(matched[j]), query)
+		if ci != cj {
+			return ci
+		}
+		return len(matched[i]) < len(matched[j])
+	})
+	return matched
+}
+
+func fuzzyMatch(s, query string) bool {
+	for _, c := range query {
+		i := strings.IndexRune(s, c)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(string(c)):]
+	}
+	return true
+}
+
+// pickMissing asks users to select the cluster, the task definition and the container which are not provided.
+func (r *runTask) pickMissing(profile, region string) error {
+	if (r.cluster != "" || r.clusterTag != "") && r.taskDefinition != "" && r.container != "" && (r.command != "" || r.script.enabled()) {
+		return nil
+	}
+	cfg, err := task.NewConfig(profile, region, configOptions()...)
+	if err != nil {
+		return errors.Wrap(err, "Failed to create AWS Session")
+	}
+	awsECS := ecs.NewFromConfig(cfg)
+	taskDefinition := task.NewTaskDefinition(awsECS)
+	ctx := context.Background()
+	p := newPicker()
+
+	if r.cluster == "" && r.clusterTag == "" {
+		clusters, err := task.ClusterNames(ctx, awsECS)
+		if err != nil {
+			return err
+		}
+		if r.cluster, err = p.pick("cluster", clusters); err != nil {
+			return err
+		}
+	}
+	if r.taskDefinition == "" {
+		families, err := taskDefinition.ListFamilies(ctx, "")
+		if err != nil {
+			return err
+		}
+		family, err := p.pick("task definition family", families)
+		if err != nil {
+			return err
+		}
+		revisions, err := taskDefinition.ListRevisions(ctx, family)
+		if err != nil {
+			return err
+		}
+		if r.taskDefinition, err = p.pick("revision", revisions); err != nil {
+			return err
+		}
+	}
+	if r.container == "" {
+		taskDef, err := taskDefinition.DescribeTaskDefinition(ctx, r.taskDefinition)
+		if err != nil {
+			return err
+		}
+		if r.container, err = p.pick("container", taskDefinition.ContainerNames(taskDef)); err != nil {
+			return err
+		}
+	}
+	if r.command == "" && !r.script.enabled() {
+		if r.command, err = p.input("Command"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
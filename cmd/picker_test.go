@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	cases := []struct {
+		s        string
+		query    string
+		expected bool
+	}{
+		{"my-web-app", "web", true},
+		{"my-web-app", "mwa", true},
+		{"my-web-app", "", true},
+		{"my-web-app", "awm", false},
+		{"web", "webapp", false},
+		{"日本語のタスク", "日タ", true},
+	}
+	for _, c := range cases {
+		if matched := fuzzyMatch(c.s, c.query); matched != c.expected {
+			t.Errorf("Match of %q with %q is invalid: %t", c.s, c.query, matched)
+		}
+	}
+}
+
+func TestFuzzyFilter(t *testing.T) {
+	items := []string{"my-web-app", "web", "backend-worker", "wbe", "Web-Admin"}
+	cases := []struct {
+		query    string
+		expected []string
+	}{
+		{"web", []string{"web", "Web-Admin", "my-web-app"}},
+		{"WEB", []string{"web", "Web-Admin", "my-web-app"}},
+		{"bw", []string{"backend-worker"}},
+		{"we", []string{"web", "Web-Admin", "my-web-app", "wbe", "backend-worker"}},
+		{"xyz", []string{}},
+	}
+	for _, c := range cases {
+		if filtered := fuzzyFilter(items, c.query); !reflect.DeepEqual(filtered, c.expected) {
+			t.Errorf("Filtered items of %q is invalid: %v", c.query, filtered)
+		}
+	}
+}
@@ -1,6 +1,11 @@
 package cmd
 
 import (
+	"os"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/h3poteto/ecs-task/pkg/task"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -17,12 +22,22 @@ func init() {
 	RootCmd.PersistentFlags().StringP("profile", "", "", "AWS profile (detault is none, and use environment variables)")
 	RootCmd.PersistentFlags().StringP("region", "", "", "AWS region (default is none, and use AWS_DEFAULT_REGION)")
 	RootCmd.PersistentFlags().BoolP("verbose", "v", false, "Enable verbose mode")
+	RootCmd.PersistentFlags().StringP("output", "o", outputText, "Output format (text, json or table)")
+	RootCmd.PersistentFlags().BoolP("fips", "", false, "Use FIPS endpoints of AWS services")
+	RootCmd.PersistentFlags().BoolP("dual-stack", "", false, "Use dual-stack (IPv6) endpoints of AWS services")
+	RootCmd.PersistentFlags().BoolP("debug-aws", "", false, "Write requests and responses of AWS API calls to stderr. Credentials and values of environment variables are redacted.")
 	viper.BindPFlag("profile", RootCmd.PersistentFlags().Lookup("profile"))
 	viper.BindPFlag("region", RootCmd.PersistentFlags().Lookup("region"))
 	viper.BindPFlag("verbose", RootCmd.PersistentFlags().Lookup("verbose"))
+	viper.BindPFlag("output", RootCmd.PersistentFlags().Lookup("output"))
+	viper.BindPFlag("fips", RootCmd.PersistentFlags().Lookup("fips"))
+	viper.BindPFlag("dual-stack", RootCmd.PersistentFlags().Lookup("dual-stack"))
+	viper.BindPFlag("debug-aws", RootCmd.PersistentFlags().Lookup("debug-aws"))
 
 	RootCmd.AddCommand(
 		runTaskCmd(),
+		historyCmd(),
+		composeCmd(),
 		versionCmd(),
 	)
 }
@@ -30,3 +45,17 @@ func init() {
 func generalConfig() (string, string, bool) {
 	return viper.GetString("profile"), viper.GetString("region"), viper.GetBool("verbose")
 }
+
+// debugLoggingOptions are shared by all AWS configs, so that their debug logs are not interleaved.
+var debugLoggingOptions = sync.OnceValue(func() []func(*config.LoadOptions) error {
+	return task.DebugLoggingOptions(os.Stderr)
+})
+
+// configOptions returns options to load AWS config, which are provided by general flags.
+func configOptions() []func(*config.LoadOptions) error {
+	fns := task.EndpointOptions(viper.GetBool("fips"), viper.GetBool("dual-stack"))
+	if viper.GetBool("debug-aws") {
+		fns = append(fns, debugLoggingOptions()...)
+	}
+	return fns
+}
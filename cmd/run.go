@@ -1,15 +1,28 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/h3poteto/ecs-task/pkg/history"
 	"github.com/h3poteto/ecs-task/pkg/task"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
 )
 
 type runTask struct {
 	cluster         string
+	clusterTag      string
 	container       string
 	taskDefinition  string
 	command         string
@@ -21,6 +34,17 @@ type runTask struct {
 	platformVersion string
 	taskSizeCpu     string
 	taskSizeMemory  string
+	logFormat       string
+	logFields       []string
+	webhookURL      string
+	webhookSecret   string
+	slackWebhookURL string
+	snsTopicArn     string
+	pushgatewayURL  string
+	pushgatewayJob  string
+	history         historyStore
+	script          scriptFile
+	logsProfile     string
 }
 
 func runTaskCmd() *cobra.Command {
@@ -30,11 +54,18 @@ func runTaskCmd() *cobra.Command {
 		Short: "Run a task on ECS",
 		Run:   r.run,
 	}
-
 	flags := cmd.Flags()
-	flags.StringVarP(&r.cluster, "cluster", "c", "", "Name of ECS Cluster")
 	flags.StringVar(&r.container, "container", "", "Name of container name in task definition")
 	flags.StringVarP(&r.taskDefinition, "task-definition", "d", "", "Name of task definition to run task. Family and revision (family:revision), only Family or full ARN")
+	r.addFlags(flags)
+	registerCompletions(cmd)
+
+	return cmd
+}
+
+func (r *runTask) addFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&r.cluster, "cluster", "c", "", "Name of ECS Cluster. Provide comma-separated names (cluster-a,cluster-b), if you want to run the task on multiple clusters in parallel.")
+	flags.StringVar(&r.clusterTag, "cluster-tag", "", "Run the task on all clusters which have the tag in parallel. Provide key=value, or only key to match any value.")
 	flags.StringVar(&r.command, "command", "", "Command which you want to run")
 	flags.StringVarP(&r.subnets, "subnets", "s", "", "Provide subnet IDs with comma-separated string (subnet-12abcde,subnet-34abcde). This param is necessary, if you set farage flag.")
 	flags.StringVarP(&r.securityGroups, "security-groups", "g", "", "Provide security group IDs with comma-separated string (sg-0123asdb,sg-2345asdf), if you want to attach the security groups to ENI of the task.")
@@ -44,8 +75,17 @@ func runTaskCmd() *cobra.Command {
 	flags.StringVarP(&r.platformVersion, "platform-version", "p", "", "The platform version that your tasks in the service are running on. A platform version is specified only for tasks using the Fargate launch type. If one isn't specified, the LATEST platform version is used by default.")
 	flags.StringVar(&r.taskSizeCpu, "task-size-cpu", "", "The hard limit of CPU units to present for the task. If both task-size-cpu and task-size-memory are set, overwrite task definition.")
 	flags.StringVar(&r.taskSizeMemory, "task-size-memory", "", "The hard limit of memory to present to the task. If both task-size-cpu and task-size-memory are set, overwrite task definition.")
-
-	return cmd
+	flags.StringVar(&r.logFormat, "log-format", "raw", "Format of log messages which are JSON objects. raw prints them as they are, fields extracts and colorizes the fields specified by log-fields, pretty indents them.")
+	flags.StringSliceVar(&r.logFields, "log-fields", task.DefaultLogFields, "Fields which are extracted from JSON log messages when log-format is fields.")
+	flags.StringVar(&r.webhookURL, "webhook-url", "", "URL which the result of the task is posted to as JSON, when the task is started, succeeded or failed.")
+	flags.StringVar(&r.webhookSecret, "webhook-secret", "", "Secret to sign the webhook payload with HMAC-SHA256. The signature is set to X-Ecs-Task-Signature header. If it is not set, ECS_TASK_WEBHOOK_SECRET environment variable is used.")
+	flags.StringVar(&r.slackWebhookURL, "slack-webhook-url", "", "Slack Incoming Webhook URL which is notified when the task is started, succeeded or failed.")
+	flags.StringVar(&r.snsTopicArn, "sns-topic-arn", "", "SNS topic ARN which the result of the task is published to, when the task is started, succeeded or failed.")
+	flags.StringVar(&r.pushgatewayURL, "pushgateway-url", "", "Prometheus Pushgateway URL which metrics of the run are pushed to, when the run is finished.")
+	flags.StringVar(&r.pushgatewayJob, "pushgateway-job", task.DefaultPushgatewayJob, "Job label of metrics which are pushed to Pushgateway.")
+	r.history.addFlags(flags)
+	r.script.addFlags(flags)
+	flags.StringVar(&r.logsProfile, "logs-profile", "", "AWS profile to read CloudWatch Logs, if logs are stored in another account (default is same as profile)")
 }
 
 func (r *runTask) run(cmd *cobra.Command, args []string) {
@@ -53,11 +93,218 @@ func (r *runTask) run(cmd *cobra.Command, args []string) {
 	if !verbose {
 		log.SetLevel(log.WarnLevel)
 	}
-	t, err := task.NewTask(r.cluster, r.container, r.taskDefinition, r.command, r.fargate, r.subnets, r.securityGroups, r.platformVersion, (time.Duration(r.timeout) * time.Second), r.timestampFormat, profile, region, r.taskSizeCpu, r.taskSizeMemory)
+	format, err := outputFormat()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if interactive() {
+		if err := r.pickMissing(profile, region); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if r.script.enabled() {
+		if r.command != "" {
+			log.Fatal("Provide either command or script")
+		}
+		r.command, err = r.script.command(profile, region)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	clusters, err := r.clusters(profile, region)
+	if err != nil {
+		log.Fatal(err)
+	}
+	cluster := ""
+	if len(clusters) > 0 {
+		cluster = clusters[0]
+	}
+	opts, err := r.notifiers(profile, region)
 	if err != nil {
 		log.Fatal(err)
 	}
-	if err := t.Run(); err != nil {
+	if r.logsProfile != "" {
+		cfg, err := task.NewConfig(r.logsProfile, region, configOptions()...)
+		if err != nil {
+			log.Fatal(errors.Wrap(err, "Failed to create AWS Session"))
+		}
+		opts = append(opts, task.WithLogsConfig(cfg))
+	}
+	opts = append(opts, task.WithConfigOptions(configOptions()...))
+	t, err := task.NewTask(cluster, r.container, r.taskDefinition, r.command, r.fargate, r.subnets, r.securityGroups, r.platformVersion, (time.Duration(r.timeout) * time.Second), r.timestampFormat, profile, region, r.taskSizeCpu, r.taskSizeMemory, opts...)
+	if err != nil {
 		log.Fatal(err)
 	}
+	t.LogFormat, err = task.ParseLogFormat(r.logFormat)
+	if err != nil {
+		log.Fatal(err)
+	}
+	t.LogFields = r.logFields
+	t.LogColor = term.IsTerminal(int(os.Stdout.Fd()))
+	if format != outputText {
+		// Keep stdout for the structured output.
+		t.LogWriter = os.Stderr
+		t.LogColor = term.IsTerminal(int(os.Stderr.Fd()))
+	}
+
+	if len(clusters) > 1 || r.clusterTag != "" {
+		results, err := t.RunClusters(clusters)
+		if outErr := printOutput(runResults(results)); outErr != nil {
+			log.Fatal(outErr)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	result, err := t.RunWithResult()
+	if outErr := printOutput(runResult{result}); outErr != nil {
+		log.Fatal(outErr)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// clusters returns names of clusters which are provided by cluster and cluster-tag flags.
+func (r *runTask) clusters(profile, region string) ([]string, error) {
+	clusters := []string{}
+	for _, c := range strings.Split(r.cluster, ",") {
+		if len(c) > 0 {
+			clusters = append(clusters, c)
+		}
+	}
+	if r.clusterTag == "" {
+		return clusters, nil
+	}
+
+	key, value, _ := strings.Cut(r.clusterTag, "=")
+	cfg, err := task.NewConfig(profile, region, configOptions()...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create AWS Session")
+	}
+	tagged, err := task.ClustersByTag(context.Background(), ecs.NewFromConfig(cfg), key, value)
+	if err != nil {
+		return nil, err
+	}
+	if len(tagged) == 0 {
+		return nil, errors.Errorf("There are no clusters which have the tag: %s", r.clusterTag)
+	}
+	for _, c := range tagged {
+		if !contains(clusters, c) {
+			clusters = append(clusters, c)
+		}
+	}
+	return clusters, nil
+}
+
+// notifiers returns options to register notifiers which are provided by flags.
+func (r *runTask) notifiers(profile, region string) ([]task.Option, error) {
+	opts := []task.Option{}
+	if r.webhookURL != "" {
+		opts = append(opts, task.WithNotifier(task.NewWebhook(r.webhookURL, r.webhookSecret)))
+	}
+	if r.slackWebhookURL != "" {
+		opts = append(opts, task.WithNotifier(task.NewSlackNotifier(r.slackWebhookURL)))
+	}
+	if r.pushgatewayURL != "" {
+		opts = append(opts, task.WithNotifier(task.NewPushgateway(r.pushgatewayURL, r.pushgatewayJob)))
+	}
+	if r.snsTopicArn == "" && !r.history.enabled() {
+		return opts, nil
+	}
+
+	cfg, err := task.NewConfig(profile, region, configOptions()...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create AWS Session")
+	}
+	if r.snsTopicArn != "" {
+		opts = append(opts, task.WithNotifier(task.NewSNSNotifier(sns.NewFromConfig(cfg), r.snsTopicArn)))
+	}
+	if r.history.enabled() {
+		ctx := context.Background()
+		store, err := r.history.store(cfg)
+		if err != nil {
+			return nil, err
+		}
+		if err := store.Ensure(ctx); err != nil {
+			return nil, err
+		}
+		initiator, err := history.CallerIdentity(ctx, sts.NewFromConfig(cfg))
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, task.WithNotifier(history.NewRecorder(store, initiator)))
+	}
+	return opts, nil
+}
+
+// runResult is an output of run command.
+type runResult struct {
+	*task.TaskResult
+}
+
+// text returns nothing, because logs of the task are the text output of run command.
+func (r runResult) text() string {
+	return ""
+}
+
+func (r runResult) header() []string {
+	return runResults{}.header()
+}
+
+func (r runResult) rows() [][]string {
+	return runResults{r.TaskResult}.rows()
+}
+
+// runResults is an output of run command with multiple clusters.
+type runResults []*task.TaskResult
+
+// text returns a summary of each cluster, because logs of the tasks are mixed.
+func (r runResults) text() string {
+	lines := []string{}
+	for _, result := range r {
+		if result.Success {
+			lines = append(lines, fmt.Sprintf("%s: succeeded in %s", result.Cluster, result.Duration.Round(time.Second)))
+		} else {
+			lines = append(lines, fmt.Sprintf("%s: failed in %s: %s", result.Cluster, result.Duration.Round(time.Second), result.Error))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r runResults) header() []string {
+	return []string{"CLUSTER", "TASK ID", "CONTAINER", "EXIT CODE", "CAUSED BY", "DURATION", "SUCCESS"}
+}
+
+func (r runResults) rows() [][]string {
+	rows := [][]string{}
+	for _, result := range r {
+		rows = append(rows, []string{result.Cluster, result.TaskID, result.Container, exitCode(result.ExitCode), causedBy(result.CausedBy), result.Duration.Round(time.Second).String(), strconv.FormatBool(result.Success)})
+	}
+	return rows
+}
+
+func exitCode(code *int32) string {
+	if code == nil {
+		return "-"
+	}
+	return strconv.Itoa(int(*code))
+}
+
+func causedBy(container string) string {
+	if container == "" {
+		return "-"
+	}
+	return container
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/h3poteto/ecs-task/pkg/script"
+	"github.com/h3poteto/ecs-task/pkg/task"
+	shellwords "github.com/mattn/go-shellwords"
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+)
+
+// scriptFile has flags to run a local script in the container.
+type scriptFile struct {
+	path   string
+	args   string
+	bucket string
+	prefix string
+}
+
+func (s *scriptFile) addFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&s.path, "script", "", "Local script file which is run in the container instead of command. The script needs a shebang such as #!/bin/sh.")
+	flags.StringVar(&s.args, "script-args", "", "Arguments which are passed to the script")
+	flags.StringVar(&s.bucket, "script-bucket", "", "S3 bucket name to upload the script, if it is too large to embed in the command. The container downloads it with a presigned URL using curl or wget.")
+	flags.StringVar(&s.prefix, "script-prefix", "ecs-task/scripts/", "Prefix of S3 objects to upload the script.")
+}
+
+// enabled returns whether the script is specified.
+func (s *scriptFile) enabled() bool {
+	return s.path != ""
+}
+
+// command returns the command which runs the script in the container.
+func (s *scriptFile) command(profile, region string) (string, error) {
+	f, err := script.Load(s.path)
+	if err != nil {
+		return "", err
+	}
+	args, err := shellwords.Parse(s.args)
+	if err != nil {
+		return "", errors.Wrap(err, "Parse error")
+	}
+	// Small scripts are embedded in the command, so they do not need AWS config and the S3 client.
+	if f.Inline() {
+		return shellJoin(f.InlineCommand(args)), nil
+	}
+	cfg, err := task.NewConfig(profile, region, configOptions()...)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to create AWS Session")
+	}
+	command, err := script.NewUploader(s3.NewFromConfig(cfg), s.bucket, s.prefix).Command(context.Background(), f, args)
+	if err != nil {
+		return "", err
+	}
+	return shellJoin(command), nil
+}
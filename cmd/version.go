@@ -16,12 +16,33 @@ func versionCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "version",
 		Short: "Print the version number",
-		Run: func(cmd *cobra.Command, args []string) {
-			fmt.Printf("Version : %s\n", version)
-			fmt.Printf("Revision: %s\n", revision)
-			fmt.Printf("Build   : %s\n", build)
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return printOutput(versionInfo{
+				Version:  version,
+				Revision: revision,
+				Build:    build,
+			})
 		},
 	}
 
 	return cmd
 }
+
+// versionInfo is an output of version command.
+type versionInfo struct {
+	Version  string `json:"version"`
+	Revision string `json:"revision"`
+	Build    string `json:"build"`
+}
+
+func (v versionInfo) text() string {
+	return fmt.Sprintf("Version : %s\nRevision: %s\nBuild   : %s", v.Version, v.Revision, v.Build)
+}
+
+func (v versionInfo) header() []string {
+	return []string{"VERSION", "REVISION", "BUILD"}
+}
+
+func (v versionInfo) rows() [][]string {
+	return [][]string{{v.Version, v.Revision, v.Build}}
+}
@@ -0,0 +1,347 @@
+/*
+Package compose converts a service of docker-compose.yml to an ECS task definition.
+
+Only the fields which make sense for one-off tasks are converted: image, command, entrypoint,
+environment, working_dir, user, mem_limit, mem_reservation and logging.
+Variable interpolation like ${VAR} is not supported, so environment values which have it are rejected.
+Environment variables without values are rejected too, unless TaskDefinitionOptions.HostEnvironment is set,
+because values of the current environment variables would be stored in the task definition.
+
+For example:
+
+	project, err := compose.Load("docker-compose.yml")
+	if err != nil {
+	    return err
+	}
+	input, err := project.TaskDefinition("job", &compose.TaskDefinitionOptions{Family: "job"})
+*/
+package compose
+
+import (
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	ecstypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	shellwords "github.com/mattn/go-shellwords"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Project is a docker-compose.yml.
+type Project struct {
+	Services map[string]*Service `yaml:"services"`
+}
+
+// Service is a service in docker-compose.yml.
+type Service struct {
+	Image          string      `yaml:"image"`
+	Command        Command     `yaml:"command"`
+	Entrypoint     Command     `yaml:"entrypoint"`
+	Environment    Environment `yaml:"environment"`
+	WorkingDir     string      `yaml:"working_dir"`
+	User           string      `yaml:"user"`
+	MemLimit       string      `yaml:"mem_limit"`
+	MemReservation string      `yaml:"mem_reservation"`
+	Logging        *Logging    `yaml:"logging"`
+}
+
+// Logging is a logging configuration of the service.
+type Logging struct {
+	Driver  string            `yaml:"driver"`
+	Options map[string]string `yaml:"options"`
+}
+
+// Command is a command which is written as a string or a list.
+type Command []string
+
+// UnmarshalYAML parses a string command as a shell does.
+func (c *Command) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		args, err := shellwords.Parse(value.Value)
+		if err != nil {
+			return errors.Wrap(err, "Parse error")
+		}
+		*c = args
+		return nil
+	}
+	var args []string
+	if err := value.Decode(&args); err != nil {
+		return err
+	}
+	*c = args
+	return nil
+}
+
+// Environment is environment variables which are written as a map or a list of KEY=VALUE.
+// The value is nil, if it is omitted like KEY in a list or KEY: in a map.
+type Environment map[string]*string
+
+// UnmarshalYAML reads environment variables from a map or a list.
+func (e *Environment) UnmarshalYAML(value *yaml.Node) error {
+	env := Environment{}
+	switch value.Kind {
+	case yaml.MappingNode:
+		var m map[string]*string
+		if err := value.Decode(&m); err != nil {
+			return err
+		}
+		for k, v := range m {
+			env[k] = v
+		}
+	case yaml.SequenceNode:
+		var list []string
+		if err := value.Decode(&list); err != nil {
+			return err
+		}
+		for _, item := range list {
+			k, v, ok := strings.Cut(item, "=")
+			if ok {
+				env[k] = &v
+			} else {
+				env[k] = nil
+			}
+		}
+	default:
+		return errors.New("environment must be a map or a list")
+	}
+	*e = env
+	return nil
+}
+
+// Load reads docker-compose.yml.
+func Load(path string) (*Project, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse parses contents of docker-compose.yml.
+func Parse(data []byte) (*Project, error) {
+	var project Project
+	if err := yaml.Unmarshal(data, &project); err != nil {
+		return nil, errors.Wrap(err, "Failed to parse compose file")
+	}
+	return &project, nil
+}
+
+// TaskDefinitionOptions has parameters of the task definition which are not written in docker-compose.yml.
+type TaskDefinitionOptions struct {
+	// Family of the task definition. If it is empty, the service name is used.
+	Family string
+	// If you set true, the task definition is compatible with Fargate.
+	Fargate bool
+	// Task size. They are required for Fargate.
+	Cpu    string
+	Memory string
+	// Roles of the task. ExecutionRoleArn is required to use awslogs on Fargate.
+	ExecutionRoleArn string
+	TaskRoleArn      string
+	// awslogs options which are used when the service does not use awslogs driver.
+	// If LogGroup is empty, "/ecs-task/<family>" is used, and the log group is created by the execution role.
+	// LogRegion is required in this case.
+	LogGroup        string
+	LogStreamPrefix string
+	LogRegion       string
+	// If you set true, values of environment variables which are omitted in the service are read from the current environment variables.
+	// Note that they are stored in the task definition, so anyone who can describe the task definition can read them.
+	HostEnvironment bool
+}
+
+// TaskDefinition returns an input of RegisterTaskDefinition for the service.
+// The name of the container is the service name.
+func (p *Project) TaskDefinition(serviceName string, opts *TaskDefinitionOptions) (*ecs.RegisterTaskDefinitionInput, error) {
+	service, ok := p.Services[serviceName]
+	if !ok || service == nil {
+		return nil, errors.Errorf("Cannot find service: %s", serviceName)
+	}
+	if service.Image == "" {
+		return nil, errors.Errorf("Image is required for service %s, because build is not supported", serviceName)
+	}
+	family := opts.Family
+	if family == "" {
+		family = serviceName
+	}
+
+	logging, err := logConfiguration(service.Logging, family, opts)
+	if err != nil {
+		return nil, err
+	}
+	environment, err := service.Environment.keyValuePairs(opts.HostEnvironment)
+	if err != nil {
+		return nil, err
+	}
+	container := ecstypes.ContainerDefinition{
+		Name:             aws.String(serviceName),
+		Image:            aws.String(service.Image),
+		Essential:        aws.Bool(true),
+		Command:          service.Command,
+		EntryPoint:       service.Entrypoint,
+		Environment:      environment,
+		LogConfiguration: logging,
+	}
+	if service.WorkingDir != "" {
+		container.WorkingDirectory = aws.String(service.WorkingDir)
+	}
+	if service.User != "" {
+		container.User = aws.String(service.User)
+	}
+	if service.MemLimit != "" {
+		memory, err := parseMemory(service.MemLimit)
+		if err != nil {
+			return nil, err
+		}
+		container.Memory = aws.Int32(memory)
+	}
+	if service.MemReservation != "" {
+		memory, err := parseMemory(service.MemReservation)
+		if err != nil {
+			return nil, err
+		}
+		container.MemoryReservation = aws.Int32(memory)
+	}
+	if opts.Memory == "" && container.Memory == nil && container.MemoryReservation == nil {
+		return nil, errors.New("Memory is required. Please provide task size memory, or mem_limit or mem_reservation of the service")
+	}
+
+	input := &ecs.RegisterTaskDefinitionInput{
+		Family:               aws.String(family),
+		ContainerDefinitions: []ecstypes.ContainerDefinition{container},
+	}
+	if opts.Cpu != "" {
+		input.Cpu = aws.String(opts.Cpu)
+	}
+	if opts.Memory != "" {
+		input.Memory = aws.String(opts.Memory)
+	}
+	if opts.ExecutionRoleArn != "" {
+		input.ExecutionRoleArn = aws.String(opts.ExecutionRoleArn)
+	}
+	if opts.TaskRoleArn != "" {
+		input.TaskRoleArn = aws.String(opts.TaskRoleArn)
+	}
+	if opts.Fargate {
+		if opts.Cpu == "" || opts.Memory == "" {
+			return nil, errors.New("Task size cpu and memory are required for Fargate")
+		}
+		input.RequiresCompatibilities = []ecstypes.Compatibility{ecstypes.CompatibilityFargate}
+		input.NetworkMode = ecstypes.NetworkModeAwsvpc
+	}
+	return input, nil
+}
+
+// logConfiguration returns awslogs configuration. If the service uses awslogs driver, the options are used as they are.
+func logConfiguration(logging *Logging, family string, opts *TaskDefinitionOptions) (*ecstypes.LogConfiguration, error) {
+	if logging != nil && logging.Driver == string(ecstypes.LogDriverAwslogs) {
+		return &ecstypes.LogConfiguration{
+			LogDriver: ecstypes.LogDriverAwslogs,
+			Options:   logging.Options,
+		}, nil
+	}
+	if opts.LogRegion == "" {
+		return nil, errors.New("Log region is required")
+	}
+	options := map[string]string{
+		"awslogs-group":         opts.LogGroup,
+		"awslogs-stream-prefix": opts.LogStreamPrefix,
+		"awslogs-region":        opts.LogRegion,
+	}
+	if opts.LogGroup == "" {
+		options["awslogs-group"] = "/ecs-task/" + family
+		options["awslogs-create-group"] = "true"
+	}
+	if opts.LogStreamPrefix == "" {
+		options["awslogs-stream-prefix"] = "ecs-task"
+	}
+	return &ecstypes.LogConfiguration{
+		LogDriver: ecstypes.LogDriverAwslogs,
+		Options:   options,
+	}, nil
+}
+
+// interpolation matches variable interpolation of docker-compose, and $$ which is an escaped $.
+var interpolation = regexp.MustCompile(`\$(\$|\{|[A-Za-z_])`)
+
+// keyValuePairs returns environment variables sorted by the name.
+// Omitted values are read from the current environment variables only if hostEnvironment is true.
+func (e Environment) keyValuePairs(hostEnvironment bool) ([]ecstypes.KeyValuePair, error) {
+	keys := []string{}
+	for k := range e {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := []ecstypes.KeyValuePair{}
+	for _, k := range keys {
+		var value string
+		if e[k] == nil {
+			if !hostEnvironment {
+				return nil, errors.Errorf("Environment variable %s does not have a value. Values of the current environment variables are not read unless they are allowed, because they are stored in the task definition", k)
+			}
+			v, ok := os.LookupEnv(k)
+			if !ok {
+				continue
+			}
+			value = v
+		} else {
+			v, err := literal(k, *e[k])
+			if err != nil {
+				return nil, err
+			}
+			value = v
+		}
+		pairs = append(pairs, ecstypes.KeyValuePair{
+			Name:  aws.String(k),
+			Value: aws.String(value),
+		})
+	}
+	return pairs, nil
+}
+
+// literal returns the value whose $$ are unescaped. It returns an error if the value has variable interpolation.
+func literal(key, value string) (string, error) {
+	var err error
+	unescaped := interpolation.ReplaceAllStringFunc(value, func(m string) string {
+		if m == "$$" {
+			return "$"
+		}
+		err = errors.Errorf("Environment variable %s has variable interpolation, which is not supported. Use $$ for a literal $", key)
+		return m
+	})
+	return unescaped, err
+}
+
+// minMemory is the minimum memory of a container in MiB which ECS accepts.
+const minMemory = 4
+
+// parseMemory parses a byte value of docker-compose like 512m, 1.5g or 536870912, and returns MiB.
+func parseMemory(value string) (int32, error) {
+	v := strings.ToLower(strings.TrimSpace(value))
+	v = strings.TrimSuffix(v, "b")
+	unit := float64(1)
+	for suffix, u := range map[string]float64{"k": 1 << 10, "m": 1 << 20, "g": 1 << 30} {
+		if strings.HasSuffix(v, suffix) {
+			v = strings.TrimSuffix(v, suffix)
+			unit = u
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, errors.Errorf("Invalid memory: %s", value)
+	}
+	mib := math.Floor(n * unit / (1 << 20))
+	if mib < minMemory {
+		return 0, errors.Errorf("Memory must be at least %d MiB: %s", minMemory, value)
+	}
+	if mib > math.MaxInt32 {
+		return 0, errors.Errorf("Memory is too large: %s", value)
+	}
+	return int32(mib), nil
+}
@@ -0,0 +1,186 @@
+package compose
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ecstypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+const composeFile = `
+services:
+  job:
+    image: my-image:latest
+    command: bundle exec rake "db:migrate VERSION=1"
+    entrypoint: ["/entrypoint.sh"]
+    environment:
+      RAILS_ENV: production
+      PRICE: $$5
+      FROM_HOST:
+    working_dir: /app
+    mem_limit: 1g
+  list:
+    image: my-image:latest
+    command: ["echo", "hoge"]
+    environment:
+      - A=1
+      - B
+      - C
+    mem_reservation: 512m
+    logging:
+      driver: awslogs
+      options:
+        awslogs-group: my-group
+        awslogs-region: us-east-1
+        awslogs-stream-prefix: my-prefix
+  build:
+    build: .
+  interpolation:
+    image: my-image:latest
+    environment:
+      TAG: ${TAG}
+    mem_limit: 1g
+`
+
+func TestTaskDefinition(t *testing.T) {
+	t.Setenv("FROM_HOST", "host")
+	t.Setenv("B", "2")
+	project, err := Parse([]byte(composeFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input, err := project.TaskDefinition("job", &TaskDefinitionOptions{LogRegion: "ap-northeast-1", HostEnvironment: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *input.Family != "job" {
+		t.Errorf("Family is invalid: %s", *input.Family)
+	}
+	container := input.ContainerDefinitions[0]
+	if *container.Name != "job" || *container.Image != "my-image:latest" || *container.WorkingDirectory != "/app" {
+		t.Errorf("Container is invalid: %+v", container)
+	}
+	expectedCommand := []string{"bundle", "exec", "rake", "db:migrate VERSION=1"}
+	if len(container.Command) != len(expectedCommand) {
+		t.Fatalf("Command is invalid: %v", container.Command)
+	}
+	for i := range expectedCommand {
+		if container.Command[i] != expectedCommand[i] {
+			t.Errorf("Command is invalid: %v", container.Command)
+		}
+	}
+	if len(container.EntryPoint) != 1 || container.EntryPoint[0] != "/entrypoint.sh" {
+		t.Errorf("Entrypoint is invalid: %v", container.EntryPoint)
+	}
+	env := map[string]string{}
+	for _, e := range container.Environment {
+		env[*e.Name] = *e.Value
+	}
+	if env["RAILS_ENV"] != "production" || env["PRICE"] != "$5" || env["FROM_HOST"] != "host" {
+		t.Errorf("Environment is invalid: %v", env)
+	}
+	if *container.Memory != 1024 {
+		t.Errorf("Memory is invalid: %d", *container.Memory)
+	}
+	options := container.LogConfiguration.Options
+	if options["awslogs-group"] != "/ecs-task/job" || options["awslogs-create-group"] != "true" || options["awslogs-region"] != "ap-northeast-1" {
+		t.Errorf("Log configuration is invalid: %v", options)
+	}
+}
+
+func TestTaskDefinitionWithAwslogs(t *testing.T) {
+	t.Setenv("B", "2")
+	project, err := Parse([]byte(composeFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input, err := project.TaskDefinition("list", &TaskDefinitionOptions{
+		Family:  "my-family",
+		Fargate: true,
+		Cpu:     "256",
+		Memory:  "512",
+		// C is not set, so it is omitted.
+		HostEnvironment: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *input.Family != "my-family" || input.NetworkMode != ecstypes.NetworkModeAwsvpc {
+		t.Errorf("Task definition is invalid: %+v", input)
+	}
+	container := input.ContainerDefinitions[0]
+	if len(container.Command) != 2 || container.Command[1] != "hoge" {
+		t.Errorf("Command is invalid: %v", container.Command)
+	}
+	expectedEnv := []ecstypes.KeyValuePair{
+		{Name: aws.String("A"), Value: aws.String("1")},
+		{Name: aws.String("B"), Value: aws.String("2")},
+	}
+	if len(container.Environment) != len(expectedEnv) {
+		t.Fatalf("Environment is invalid: %v", container.Environment)
+	}
+	for i := range expectedEnv {
+		if *container.Environment[i].Name != *expectedEnv[i].Name || *container.Environment[i].Value != *expectedEnv[i].Value {
+			t.Errorf("Environment is invalid: %v", container.Environment)
+		}
+	}
+	if *container.MemoryReservation != 512 {
+		t.Errorf("Memory reservation is invalid: %d", *container.MemoryReservation)
+	}
+	if container.LogConfiguration.Options["awslogs-group"] != "my-group" {
+		t.Errorf("Log configuration is invalid: %v", container.LogConfiguration.Options)
+	}
+}
+
+func TestTaskDefinitionErrors(t *testing.T) {
+	project, err := Parse([]byte(composeFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := project.TaskDefinition("unknown", &TaskDefinitionOptions{}); err == nil {
+		t.Error("Does not error when service does not exist")
+	}
+	if _, err := project.TaskDefinition("build", &TaskDefinitionOptions{}); err == nil {
+		t.Error("Does not error when service does not have image")
+	}
+	if _, err := project.TaskDefinition("list", &TaskDefinitionOptions{Fargate: true, HostEnvironment: true}); err == nil {
+		t.Error("Does not error when task size is not provided for Fargate")
+	}
+	if _, err := project.TaskDefinition("job", &TaskDefinitionOptions{LogRegion: "ap-northeast-1"}); err == nil {
+		t.Error("Does not error when environment variable does not have a value")
+	}
+	if _, err := project.TaskDefinition("interpolation", &TaskDefinitionOptions{LogRegion: "ap-northeast-1"}); err == nil {
+		t.Error("Does not error when environment variable has variable interpolation")
+	}
+}
+
+func TestParseMemory(t *testing.T) {
+	cases := []struct {
+		value    string
+		expected int32
+		err      bool
+	}{
+		{"512m", 512, false},
+		{"512mb", 512, false},
+		{"512M", 512, false},
+		{"1g", 1024, false},
+		{"1.5g", 1536, false},
+		{"4194304", 4, false},
+		{"512", 0, true},
+		{"100k", 0, true},
+		{"3m", 0, true},
+		{"3000000g", 0, true},
+		{"foo", 0, true},
+	}
+	for _, c := range cases {
+		memory, err := parseMemory(c.value)
+		if (err != nil) != c.err {
+			t.Errorf("Error of %q is invalid: %v", c.value, err)
+		}
+		if memory != c.expected {
+			t.Errorf("Memory of %q is invalid: %d", c.value, memory)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type DynamoDBClient interface {
+	DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
+	CreateTable(ctx context.Context, params *dynamodb.CreateTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.CreateTableOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+}
+
+// DynamoDBStore stores records in a DynamoDB table.
+// The table has ID as a hash key, and the record is saved as JSON in Record attribute.
+// Family, Cluster, Initiator and RecordedAt attributes are also saved to filter records.
+type DynamoDBStore struct {
+	awsDynamoDB DynamoDBClient
+	Table       string
+}
+
+// NewDynamoDBStore returns a new DynamoDBStore struct.
+func NewDynamoDBStore(awsDynamoDB DynamoDBClient, table string) *DynamoDBStore {
+	return &DynamoDBStore{
+		awsDynamoDB: awsDynamoDB,
+		Table:       table,
+	}
+}
+
+// Ensure creates the table with on-demand capacity, if it does not exist.
+func (s *DynamoDBStore) Ensure(ctx context.Context) error {
+	_, err := s.awsDynamoDB.DescribeTable(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(s.Table),
+	})
+	if err == nil {
+		return nil
+	}
+	var notFound *dynamodbtypes.ResourceNotFoundException
+	if !errors.As(err, &notFound) {
+		return err
+	}
+
+	log.Infof("Creating DynamoDB table %s", s.Table)
+	_, err = s.awsDynamoDB.CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName: aws.String(s.Table),
+		AttributeDefinitions: []dynamodbtypes.AttributeDefinition{
+			{
+				AttributeName: aws.String("ID"),
+				AttributeType: dynamodbtypes.ScalarAttributeTypeS,
+			},
+		},
+		KeySchema: []dynamodbtypes.KeySchemaElement{
+			{
+				AttributeName: aws.String("ID"),
+				KeyType:       dynamodbtypes.KeyTypeHash,
+			},
+		},
+		BillingMode: dynamodbtypes.BillingModePayPerRequest,
+	})
+	if err != nil {
+		return errors.Wrap(err, "Failed to create DynamoDB table")
+	}
+	waiter := dynamodb.NewTableExistsWaiter(s.awsDynamoDB)
+	return waiter.Wait(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(s.Table),
+	}, 5*time.Minute)
+}
+
+// Put saves the record.
+func (s *DynamoDBStore) Put(ctx context.Context, record *Record) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	cluster := ""
+	if record.Result != nil {
+		cluster = record.Result.Cluster
+	}
+	_, err = s.awsDynamoDB.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.Table),
+		Item: map[string]dynamodbtypes.AttributeValue{
+			"ID":         &dynamodbtypes.AttributeValueMemberS{Value: record.ID},
+			"Family":     &dynamodbtypes.AttributeValueMemberS{Value: record.Family},
+			"Cluster":    &dynamodbtypes.AttributeValueMemberS{Value: cluster},
+			"Initiator":  &dynamodbtypes.AttributeValueMemberS{Value: record.Initiator},
+			"RecordedAt": &dynamodbtypes.AttributeValueMemberN{Value: strconv.FormatInt(record.RecordedAt.Unix(), 10)},
+			"Record":     &dynamodbtypes.AttributeValueMemberS{Value: string(body)},
+		},
+	})
+	return err
+}
+
+// List scans the table, and returns records which match the filter.
+func (s *DynamoDBStore) List(ctx context.Context, filter *Filter) ([]*Record, error) {
+	input := &dynamodb.ScanInput{
+		TableName: aws.String(s.Table),
+	}
+	setScanFilter(input, filter)
+
+	records := []*Record{}
+	paginator := dynamodb.NewScanPaginator(s.awsDynamoDB, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range output.Items {
+			attr, ok := item["Record"].(*dynamodbtypes.AttributeValueMemberS)
+			if !ok {
+				continue
+			}
+			var record Record
+			if err := json.Unmarshal([]byte(attr.Value), &record); err != nil {
+				log.Warnf("Failed to decode record: %v", err)
+				continue
+			}
+			if filter.Match(&record) {
+				records = append(records, &record)
+			}
+		}
+	}
+	return sortRecords(records, filter.Limit), nil
+}
+
+// setScanFilter sets a filter expression to reduce items which are returned from Scan.
+func setScanFilter(input *dynamodb.ScanInput, filter *Filter) {
+	conditions := []string{}
+	names := map[string]string{}
+	values := map[string]dynamodbtypes.AttributeValue{}
+	if filter.Family != "" {
+		conditions = append(conditions, "#family = :family")
+		names["#family"] = "Family"
+		values[":family"] = &dynamodbtypes.AttributeValueMemberS{Value: filter.Family}
+	}
+	if filter.Cluster != "" {
+		conditions = append(conditions, "#cluster = :cluster")
+		names["#cluster"] = "Cluster"
+		values[":cluster"] = &dynamodbtypes.AttributeValueMemberS{Value: filter.Cluster}
+	}
+	if filter.Initiator != "" {
+		conditions = append(conditions, "contains(#initiator, :initiator)")
+		names["#initiator"] = "Initiator"
+		values[":initiator"] = &dynamodbtypes.AttributeValueMemberS{Value: filter.Initiator}
+	}
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "#recordedAt >= :since")
+		names["#recordedAt"] = "RecordedAt"
+		values[":since"] = &dynamodbtypes.AttributeValueMemberN{Value: strconv.FormatInt(filter.Since.Unix(), 10)}
+	}
+	if !filter.Until.IsZero() {
+		conditions = append(conditions, "#recordedAt <= :until")
+		names["#recordedAt"] = "RecordedAt"
+		values[":until"] = &dynamodbtypes.AttributeValueMemberN{Value: strconv.FormatInt(filter.Until.Unix(), 10)}
+	}
+	if len(conditions) == 0 {
+		return
+	}
+	input.FilterExpression = aws.String(strings.Join(conditions, " AND "))
+	input.ExpressionAttributeNames = names
+	input.ExpressionAttributeValues = values
+}
@@ -0,0 +1,54 @@
+package history
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/h3poteto/ecs-task/pkg/task"
+)
+
+type mockedDynamoDB struct {
+	DynamoDBClient
+	items []map[string]dynamodbtypes.AttributeValue
+	input *dynamodb.ScanInput
+}
+
+func (m *mockedDynamoDB) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	m.items = append(m.items, params.Item)
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (m *mockedDynamoDB) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	m.input = params
+	return &dynamodb.ScanOutput{Items: m.items}, nil
+}
+
+func TestDynamoDBStore(t *testing.T) {
+	client := &mockedDynamoDB{}
+	store := NewDynamoDBStore(client, "table")
+	ctx := context.Background()
+	record := &Record{
+		ID:         "task-id",
+		Family:     "my-task",
+		Initiator:  "alice",
+		RecordedAt: time.Now(),
+		Result:     &task.TaskResult{Cluster: "production"},
+	}
+	if err := store.Put(ctx, record); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := store.List(ctx, &Filter{Family: "my-task", Cluster: "production"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *client.input.FilterExpression != "#family = :family AND #cluster = :cluster" {
+		t.Errorf("Filter expression is invalid: %s", *client.input.FilterExpression)
+	}
+	if len(records) != 1 || records[0].ID != "task-id" || records[0].Result.Cluster != "production" {
+		t.Errorf("Records are invalid: %v", records)
+	}
+}
@@ -0,0 +1,114 @@
+package history
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	ecstypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/h3poteto/ecs-task/pkg/task"
+)
+
+type ECSClient interface {
+	ListTasks(ctx context.Context, params *ecs.ListTasksInput, optFns ...func(*ecs.Options)) (*ecs.ListTasksOutput, error)
+	DescribeTasks(ctx context.Context, params *ecs.DescribeTasksInput, optFns ...func(*ecs.Options)) (*ecs.DescribeTasksOutput, error)
+}
+
+// ListStoppedTasks returns records of recently stopped tasks in the cluster.
+// It is a fallback when there is no history store, so only tasks which ECS still retains are listed.
+func ListStoppedTasks(ctx context.Context, awsECS ECSClient, cluster string, filter *Filter) ([]*Record, error) {
+	input := &ecs.ListTasksInput{
+		Cluster:       aws.String(cluster),
+		DesiredStatus: ecstypes.DesiredStatusStopped,
+	}
+	if filter.Family != "" {
+		input.Family = aws.String(filter.Family)
+	}
+	arns := []string{}
+	paginator := ecs.NewListTasksPaginator(awsECS, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		arns = append(arns, output.TaskArns...)
+	}
+
+	records := []*Record{}
+	// DescribeTasks accepts up to 100 tasks at once.
+	for i := 0; i < len(arns); i += 100 {
+		end := i + 100
+		if end > len(arns) {
+			end = len(arns)
+		}
+		output, err := awsECS.DescribeTasks(ctx, &ecs.DescribeTasksInput{
+			Cluster: aws.String(cluster),
+			Tasks:   arns[i:end],
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range output.Tasks {
+			record := stoppedTaskRecord(cluster, t)
+			if filter.Match(record) {
+				records = append(records, record)
+			}
+		}
+	}
+	return sortRecords(records, filter.Limit), nil
+}
+
+// stoppedTaskRecord returns a record of the stopped task.
+// The target container is the container whose command is overridden, because ecs-task overrides the command.
+func stoppedTaskRecord(cluster string, t ecstypes.Task) *Record {
+	result := &task.TaskResult{
+		Cluster:        cluster,
+		TaskDefinition: aws.ToString(t.TaskDefinitionArn),
+		TaskArn:        aws.ToString(t.TaskArn),
+		StoppedReason:  aws.ToString(t.StoppedReason),
+		StartedAt:      t.StartedAt,
+		StoppedAt:      t.StoppedAt,
+	}
+	if i := strings.LastIndex(result.TaskArn, "/"); i >= 0 {
+		result.TaskID = result.TaskArn[i+1:]
+	}
+
+	target := ""
+	if t.Overrides != nil {
+		for _, o := range t.Overrides.ContainerOverrides {
+			if len(o.Command) > 0 {
+				target = aws.ToString(o.Name)
+				result.Command = task.RedactCommand(o.Command)
+				break
+			}
+		}
+	}
+	for _, c := range t.Containers {
+		if target == "" || aws.ToString(c.Name) == target {
+			result.Container = aws.ToString(c.Name)
+			result.ExitCode = c.ExitCode
+			break
+		}
+	}
+	result.Success = result.ExitCode != nil && *result.ExitCode == 0
+
+	recordedAt := t.StoppedAt
+	if recordedAt == nil {
+		recordedAt = t.CreatedAt
+	}
+	if t.StartedAt != nil && t.StoppedAt != nil {
+		result.Duration = t.StoppedAt.Sub(*t.StartedAt)
+	}
+
+	record := &Record{
+		ID:        result.TaskID,
+		Family:    Family(result.TaskDefinition),
+		Initiator: aws.ToString(t.StartedBy),
+		Result:    result,
+	}
+	if recordedAt != nil {
+		record.RecordedAt = *recordedAt
+	}
+	return record
+}
@@ -0,0 +1,74 @@
+package history
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	ecstypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+type mockedECS struct {
+	ECSClient
+	List     ecs.ListTasksOutput
+	Describe ecs.DescribeTasksOutput
+}
+
+func (m mockedECS) ListTasks(ctx context.Context, params *ecs.ListTasksInput, optFns ...func(*ecs.Options)) (*ecs.ListTasksOutput, error) {
+	return &m.List, nil
+}
+
+func (m mockedECS) DescribeTasks(ctx context.Context, params *ecs.DescribeTasksInput, optFns ...func(*ecs.Options)) (*ecs.DescribeTasksOutput, error) {
+	return &m.Describe, nil
+}
+
+func TestListStoppedTasks(t *testing.T) {
+	stoppedAt := time.Now()
+	startedAt := stoppedAt.Add(-time.Minute)
+	client := mockedECS{
+		List: ecs.ListTasksOutput{
+			TaskArns: []string{"arn:aws:ecs:ap-northeast-1:1234567890:task/cluster/task-id"},
+		},
+		Describe: ecs.DescribeTasksOutput{
+			Tasks: []ecstypes.Task{
+				{
+					TaskArn:           aws.String("arn:aws:ecs:ap-northeast-1:1234567890:task/cluster/task-id"),
+					TaskDefinitionArn: aws.String("arn:aws:ecs:ap-northeast-1:1234567890:task-definition/my-task:3"),
+					StartedBy:         aws.String("alice"),
+					StartedAt:         &startedAt,
+					StoppedAt:         &stoppedAt,
+					Overrides: &ecstypes.TaskOverride{
+						ContainerOverrides: []ecstypes.ContainerOverride{
+							{Name: aws.String("sidecar")},
+							{Name: aws.String("task"), Command: []string{"echo", "hoge"}},
+						},
+					},
+					Containers: []ecstypes.Container{
+						{Name: aws.String("sidecar"), ExitCode: aws.Int32(0)},
+						{Name: aws.String("task"), ExitCode: aws.Int32(1)},
+					},
+				},
+			},
+		},
+	}
+
+	records, err := ListStoppedTasks(context.Background(), client, "cluster", &Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Records are invalid: %v", records)
+	}
+	record := records[0]
+	if record.ID != "task-id" || record.Family != "my-task" || record.Initiator != "alice" {
+		t.Errorf("Record is invalid: %+v", record)
+	}
+	if record.Result.Container != "task" || *record.Result.ExitCode != 1 || record.Result.Success {
+		t.Errorf("Result is invalid: %+v", record.Result)
+	}
+	if record.Result.Duration != time.Minute {
+		t.Errorf("Duration is invalid: %v", record.Result.Duration)
+	}
+}
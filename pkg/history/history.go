@@ -0,0 +1,198 @@
+/*
+Package history records results of tasks which are run by task package, and lists them.
+
+Records are stored in a DynamoDB table or under a S3 prefix, and they are kept longer than
+stopped tasks on ECS. Recorder implements task.Notifier, so you can record every run as follows.
+
+	store := history.NewDynamoDBStore(dynamodb.NewFromConfig(cfg), "ecs-task-history")
+	if err := store.Ensure(ctx); err != nil {
+	    return err
+	}
+	initiator, err := history.CallerIdentity(ctx, sts.NewFromConfig(cfg))
+	if err != nil {
+	    return err
+	}
+	t, err := task.NewTask(..., task.WithNotifier(history.NewRecorder(store, initiator)))
+
+A record is written when the task is started, and it is overwritten when the run is finished.
+So a run is recorded even if ecs-task is killed during the run, and the record remains running.
+Note that errors of notifiers are only logged, so the run is not failed even if the record can not be written.
+*/
+package history
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/h3poteto/ecs-task/pkg/task"
+	"github.com/pkg/errors"
+)
+
+// Record is a history of a run.
+type Record struct {
+	ID string `json:"id"`
+	// Family of the task definition.
+	Family string `json:"family"`
+	// Who ran the task. It is an ARN of the AWS identity usually.
+	Initiator  string           `json:"initiator"`
+	RecordedAt time.Time        `json:"recordedAt"`
+	Result     *task.TaskResult `json:"result"`
+	// Whether the run was not finished when the record was written.
+	// It remains true, if ecs-task was killed before the run was finished.
+	Running bool `json:"running,omitempty"`
+}
+
+// NewRecord returns a new Record of the result.
+func NewRecord(result *task.TaskResult, initiator string) *Record {
+	id := result.TaskID
+	if id == "" {
+		id = randomID()
+	}
+	return &Record{
+		ID:         id,
+		Family:     Family(result.TaskDefinition),
+		Initiator:  initiator,
+		RecordedAt: time.Now().UTC(),
+		Result:     result,
+	}
+}
+
+// Filter specifies records which are listed. Empty fields are ignored.
+type Filter struct {
+	Family  string
+	Cluster string
+	// Records whose initiator contains this are listed.
+	Initiator string
+	Since     time.Time
+	Until     time.Time
+	// Maximum number of records. If you set 0, all records are listed.
+	Limit int
+}
+
+// Match returns whether the record matches the filter. Records without the result never match.
+func (f *Filter) Match(r *Record) bool {
+	if r.Result == nil {
+		return false
+	}
+	if f.Family != "" && r.Family != f.Family {
+		return false
+	}
+	if f.Cluster != "" && r.Result.Cluster != f.Cluster {
+		return false
+	}
+	if f.Initiator != "" && !strings.Contains(r.Initiator, f.Initiator) {
+		return false
+	}
+	if !f.Since.IsZero() && r.RecordedAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && r.RecordedAt.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// Store saves records, and lists them.
+type Store interface {
+	// Ensure creates the table or the bucket, if it does not exist.
+	Ensure(ctx context.Context) error
+	Put(ctx context.Context, record *Record) error
+	// List returns records which match the filter, newest first.
+	List(ctx context.Context, filter *Filter) ([]*Record, error)
+}
+
+// Recorder records results of tasks to the store. It implements task.Notifier.
+type Recorder struct {
+	store     Store
+	initiator string
+	mu        sync.Mutex
+	// Records which are written when tasks are started, so that they are overwritten when the runs are finished.
+	started map[*task.TaskResult]*Record
+}
+
+// NewRecorder returns a new Recorder struct.
+func NewRecorder(store Store, initiator string) *Recorder {
+	return &Recorder{
+		store:     store,
+		initiator: initiator,
+		started:   map[*task.TaskResult]*Record{},
+	}
+}
+
+// OnStart records the result as running.
+func (r *Recorder) OnStart(ctx context.Context, result *task.TaskResult) error {
+	record := NewRecord(result, r.initiator)
+	record.Running = true
+	r.mu.Lock()
+	r.started[result] = record
+	r.mu.Unlock()
+	return r.store.Put(ctx, record)
+}
+
+// OnSuccess records the result.
+func (r *Recorder) OnSuccess(ctx context.Context, result *task.TaskResult) error {
+	return r.finish(ctx, result)
+}
+
+// OnFailure records the result.
+func (r *Recorder) OnFailure(ctx context.Context, result *task.TaskResult) error {
+	return r.finish(ctx, result)
+}
+
+// finish overwrites the record which is written when the task is started, or writes a new record if the task was not started.
+func (r *Recorder) finish(ctx context.Context, result *task.TaskResult) error {
+	r.mu.Lock()
+	record, ok := r.started[result]
+	delete(r.started, result)
+	r.mu.Unlock()
+	if !ok {
+		record = NewRecord(result, r.initiator)
+	}
+	record.Running = false
+	return r.store.Put(ctx, record)
+}
+
+type STSClient interface {
+	GetCallerIdentity(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error)
+}
+
+// CallerIdentity returns ARN of the AWS identity which runs tasks.
+// It does not fall back to the local user name, because it can be anything and records must be trustworthy.
+func CallerIdentity(ctx context.Context, awsSTS STSClient) (string, error) {
+	output, err := awsSTS.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to get caller identity")
+	}
+	if output.Arn == nil {
+		return "", errors.New("Failed to get caller identity: ARN is empty")
+	}
+	return *output.Arn, nil
+}
+
+// Family returns the family of the task definition, which is provided as full ARN, family or family:revision.
+func Family(taskDefinition string) string {
+	return task.Family(taskDefinition)
+}
+
+// sortRecords sorts records newest first, and truncates them according to the limit.
+func sortRecords(records []*Record, limit int) []*Record {
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].RecordedAt.After(records[j].RecordedAt)
+	})
+	if limit > 0 && len(records) > limit {
+		records = records[:limit]
+	}
+	return records
+}
+
+func randomID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,164 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/h3poteto/ecs-task/pkg/task"
+)
+
+type mockedSTS struct {
+	output *sts.GetCallerIdentityOutput
+	err    error
+}
+
+func (m *mockedSTS) GetCallerIdentity(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+	return m.output, m.err
+}
+
+func TestFamily(t *testing.T) {
+	tests := []struct {
+		name           string
+		taskDefinition string
+		expected       string
+	}{
+		{
+			name:           "Family",
+			taskDefinition: "my-task",
+			expected:       "my-task",
+		},
+		{
+			name:           "FamilyAndRevision",
+			taskDefinition: "my-task:3",
+			expected:       "my-task",
+		},
+		{
+			name:           "ARN",
+			taskDefinition: "arn:aws:ecs:ap-northeast-1:1234567890:task-definition/my-task:3",
+			expected:       "my-task",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if family := Family(tt.taskDefinition); family != tt.expected {
+				t.Errorf("Family is invalid: %s", family)
+			}
+		})
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	now := time.Now()
+	record := &Record{
+		Family:     "my-task",
+		Initiator:  "arn:aws:sts::1234567890:assumed-role/Admin/alice",
+		RecordedAt: now,
+		Result:     &task.TaskResult{Cluster: "production"},
+	}
+
+	tests := []struct {
+		name     string
+		filter   Filter
+		expected bool
+	}{
+		{name: "Empty", filter: Filter{}, expected: true},
+		{name: "Family", filter: Filter{Family: "my-task"}, expected: true},
+		{name: "OtherFamily", filter: Filter{Family: "other"}, expected: false},
+		{name: "Cluster", filter: Filter{Cluster: "production"}, expected: true},
+		{name: "OtherCluster", filter: Filter{Cluster: "staging"}, expected: false},
+		{name: "Initiator", filter: Filter{Initiator: "alice"}, expected: true},
+		{name: "OtherInitiator", filter: Filter{Initiator: "bob"}, expected: false},
+		{name: "InRange", filter: Filter{Since: now.Add(-time.Hour), Until: now.Add(time.Hour)}, expected: true},
+		{name: "Before", filter: Filter{Since: now.Add(time.Hour)}, expected: false},
+		{name: "After", filter: Filter{Until: now.Add(-time.Hour)}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if matched := tt.filter.Match(record); matched != tt.expected {
+				t.Errorf("Match returns %v", matched)
+			}
+		})
+	}
+}
+
+type memoryStore struct {
+	Store
+	records []*Record
+}
+
+func (m *memoryStore) Put(ctx context.Context, record *Record) error {
+	r := *record
+	m.records = append(m.records, &r)
+	return nil
+}
+
+func TestRecorder(t *testing.T) {
+	store := &memoryStore{}
+	recorder := NewRecorder(store, "alice")
+	ctx := context.Background()
+	result := &task.TaskResult{
+		TaskDefinition: "my-task:1",
+		TaskID:         "task-id",
+	}
+	if err := recorder.OnStart(ctx, result); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.records) != 1 || !store.records[0].Running {
+		t.Fatalf("Records are invalid: %v", store.records)
+	}
+	if err := recorder.OnFailure(ctx, result); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.records) != 2 {
+		t.Fatalf("Records are invalid: %v", store.records)
+	}
+	started, finished := store.records[0], store.records[1]
+	if finished.ID != "task-id" || finished.Family != "my-task" || finished.Initiator != "alice" || finished.Running {
+		t.Errorf("Record is invalid: %+v", finished)
+	}
+	if started.ID != finished.ID || !started.RecordedAt.Equal(finished.RecordedAt) {
+		t.Errorf("Finished record does not overwrite the started record: %+v, %+v", started, finished)
+	}
+
+	// A run which is failed before the task is started is recorded when it is finished.
+	if err := recorder.OnFailure(ctx, &task.TaskResult{TaskDefinition: "my-task:1"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.records) != 3 || store.records[2].ID == "" || store.records[2].Running {
+		t.Errorf("Records are invalid: %v", store.records)
+	}
+}
+
+func TestSortRecords(t *testing.T) {
+	now := time.Now()
+	records := []*Record{
+		{ID: "old", RecordedAt: now.Add(-time.Hour)},
+		{ID: "new", RecordedAt: now},
+		{ID: "middle", RecordedAt: now.Add(-time.Minute)},
+	}
+	sorted := sortRecords(records, 2)
+	if len(sorted) != 2 || sorted[0].ID != "new" || sorted[1].ID != "middle" {
+		t.Errorf("Records are invalid: %v", sorted)
+	}
+}
+
+func TestCallerIdentity(t *testing.T) {
+	arn := "arn:aws:iam::123456789012:user/alice"
+	initiator, err := CallerIdentity(context.Background(), &mockedSTS{output: &sts.GetCallerIdentityOutput{Arn: aws.String(arn)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if initiator != arn {
+		t.Errorf("Initiator is invalid: %s", initiator)
+	}
+
+	if _, err := CallerIdentity(context.Background(), &mockedSTS{err: errors.New("expired token")}); err == nil {
+		t.Error("Does not error when STS is failed")
+	}
+}
@@ -0,0 +1,191 @@
+package history
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// s3KeyTimeFormat is a format of time in object keys. Keys are sorted by the time.
+const s3KeyTimeFormat = "2006/01/02/150405"
+
+type S3Client interface {
+	HeadBucket(ctx context.Context, params *s3.HeadBucketInput, optFns ...func(*s3.Options)) (*s3.HeadBucketOutput, error)
+	CreateBucket(ctx context.Context, params *s3.CreateBucketInput, optFns ...func(*s3.Options)) (*s3.CreateBucketOutput, error)
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+}
+
+// S3Store stores records as JSON objects under the prefix of a S3 bucket.
+// Object keys are `<prefix><yyyy>/<mm>/<dd>/<hhmmss>-<id>.json`.
+type S3Store struct {
+	awsS3  S3Client
+	Bucket string
+	Prefix string
+	// Region where the bucket is created.
+	Region string
+}
+
+// NewS3Store returns a new S3Store struct.
+func NewS3Store(awsS3 S3Client, bucket, prefix, region string) *S3Store {
+	return &S3Store{
+		awsS3:  awsS3,
+		Bucket: bucket,
+		Prefix: prefix,
+		Region: region,
+	}
+}
+
+// Ensure creates the bucket, if it does not exist.
+func (s *S3Store) Ensure(ctx context.Context) error {
+	_, err := s.awsS3.HeadBucket(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(s.Bucket),
+	})
+	if err == nil {
+		return nil
+	}
+	var notFound *s3types.NotFound
+	if !errors.As(err, &notFound) {
+		return err
+	}
+
+	log.Infof("Creating S3 bucket %s", s.Bucket)
+	input := &s3.CreateBucketInput{
+		Bucket: aws.String(s.Bucket),
+	}
+	// us-east-1 does not accept the location constraint.
+	if s.Region != "" && s.Region != "us-east-1" {
+		input.CreateBucketConfiguration = &s3types.CreateBucketConfiguration{
+			LocationConstraint: s3types.BucketLocationConstraint(s.Region),
+		}
+	}
+	if _, err := s.awsS3.CreateBucket(ctx, input); err != nil {
+		return errors.Wrap(err, "Failed to create S3 bucket")
+	}
+	waiter := s3.NewBucketExistsWaiter(s.awsS3)
+	return waiter.Wait(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(s.Bucket),
+	}, 5*time.Minute)
+}
+
+// Put saves the record as a JSON object.
+func (s *S3Store) Put(ctx context.Context, record *Record) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	_, err = s.awsS3.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.Bucket),
+		Key:         aws.String(s.key(record)),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String("application/json"),
+	})
+	return err
+}
+
+// List returns records which match the filter.
+// Objects are listed from Since to Until according to their keys, so the time range reduces requests.
+// Records are read newest first, and reading stops when Limit records are found.
+func (s *S3Store) List(ctx context.Context, filter *Filter) ([]*Record, error) {
+	keys, err := s.keys(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	// Keys are sorted by the time, so the last key is the newest.
+	sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+
+	records := []*Record{}
+	for _, key := range keys {
+		if filter.Limit > 0 && len(records) >= filter.Limit {
+			break
+		}
+		record, err := s.get(ctx, key)
+		if err != nil {
+			log.Warnf("Failed to read record %s: %v", key, err)
+			continue
+		}
+		if filter.Match(record) {
+			records = append(records, record)
+		}
+	}
+	return sortRecords(records, filter.Limit), nil
+}
+
+// keys returns object keys of records between Since and Until.
+func (s *S3Store) keys(ctx context.Context, filter *Filter) ([]string, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.Bucket),
+		Prefix: aws.String(s.Prefix),
+	}
+	if !filter.Since.IsZero() {
+		input.StartAfter = aws.String(s.Prefix + filter.Since.UTC().Format(s3KeyTimeFormat))
+	}
+
+	keys := []string{}
+	paginator := s3.NewListObjectsV2Paginator(s.awsS3, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range output.Contents {
+			recordedAt, ok := s.keyTime(*object.Key)
+			if !ok {
+				continue
+			}
+			if !filter.Until.IsZero() && recordedAt.After(filter.Until) {
+				return keys, nil
+			}
+			keys = append(keys, *object.Key)
+		}
+	}
+	return keys, nil
+}
+
+func (s *S3Store) get(ctx context.Context, key string) (*Record, error) {
+	output, err := s.awsS3.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer output.Body.Close()
+	body, err := io.ReadAll(output.Body)
+	if err != nil {
+		return nil, err
+	}
+	var record Record
+	if err := json.Unmarshal(body, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (s *S3Store) key(record *Record) string {
+	return s.Prefix + record.RecordedAt.UTC().Format(s3KeyTimeFormat) + "-" + record.ID + ".json"
+}
+
+// keyTime returns the time when the record was recorded according to the key.
+func (s *S3Store) keyTime(key string) (time.Time, bool) {
+	key = strings.TrimPrefix(key, s.Prefix)
+	if len(key) < len(s3KeyTimeFormat) {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(s3KeyTimeFormat, key[:len(s3KeyTimeFormat)])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
@@ -0,0 +1,91 @@
+package history
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/h3poteto/ecs-task/pkg/task"
+)
+
+type mockedS3 struct {
+	S3Client
+	objects map[string][]byte
+	input   *s3.ListObjectsV2Input
+	gets    []string
+}
+
+func (m *mockedS3) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	m.input = params
+	contents := []s3types.Object{}
+	for _, key := range []string{"prefix/2024/01/01/000000-a.json", "prefix/2024/01/02/000000-b.json", "prefix/2024/01/03/000000-c.json"} {
+		contents = append(contents, s3types.Object{Key: aws.String(key)})
+	}
+	return &s3.ListObjectsV2Output{Contents: contents}, nil
+}
+
+func (m *mockedS3) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	m.gets = append(m.gets, *params.Key)
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(m.objects[*params.Key]))}, nil
+}
+
+func TestS3StoreList(t *testing.T) {
+	objects := map[string][]byte{}
+	for key, id := range map[string]string{
+		"prefix/2024/01/01/000000-a.json": "a",
+		"prefix/2024/01/02/000000-b.json": "b",
+		"prefix/2024/01/03/000000-c.json": "c",
+	} {
+		store := &S3Store{Prefix: "prefix/"}
+		recordedAt, _ := store.keyTime(key)
+		body, _ := json.Marshal(&Record{ID: id, RecordedAt: recordedAt, Result: &task.TaskResult{}})
+		objects[key] = body
+	}
+	client := &mockedS3{objects: objects}
+	store := NewS3Store(client, "bucket", "prefix/", "ap-northeast-1")
+
+	records, err := store.List(context.Background(), &Filter{
+		Since: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		Until: time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *client.input.StartAfter != "prefix/2024/01/01/120000" {
+		t.Errorf("StartAfter is invalid: %s", *client.input.StartAfter)
+	}
+	if len(records) != 1 || records[0].ID != "b" {
+		t.Errorf("Records are invalid: %v", records)
+	}
+
+	client.gets = nil
+	records, err = store.List(context.Background(), &Filter{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].ID != "c" || records[1].ID != "b" {
+		t.Errorf("Records are invalid: %v", records)
+	}
+	if len(client.gets) != 2 {
+		t.Errorf("Objects which are read are invalid: %v", client.gets)
+	}
+}
+
+func TestS3StoreKey(t *testing.T) {
+	store := &S3Store{Prefix: "prefix/"}
+	recordedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	key := store.key(&Record{ID: "id", RecordedAt: recordedAt})
+	if key != "prefix/2024/01/02/030405-id.json" {
+		t.Errorf("Key is invalid: %s", key)
+	}
+	parsed, ok := store.keyTime(key)
+	if !ok || !parsed.Equal(recordedAt) {
+		t.Errorf("Time of the key is invalid: %v", parsed)
+	}
+}
@@ -0,0 +1,169 @@
+/*
+Package script builds a command which runs a local script file in a container.
+
+A small script is embedded in the command as base64. A larger script is uploaded to a S3 bucket,
+and the command downloads it with a presigned URL, so the container does not need AWS credentials
+or AWS CLI, but needs curl or wget.
+
+	s, err := script.Load("./migrate.sh")
+	if err != nil {
+	    return err
+	}
+	u := script.NewUploader(s3.NewFromConfig(cfg), "my-bucket", "ecs-task/scripts/")
+	command, err := u.Command(ctx, s, []string{"--dry-run"})
+	if err != nil {
+	    return err
+	}
+	t, err := task.NewTask(..., shellJoin(command), ...)
+
+The script is executed with its shebang, so it should start with such as `#!/bin/sh`.
+*/
+package script
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// InlineLimit is the maximum size of scripts which are embedded in the command.
+// Container overrides of RunTask are limited to 8192 characters, and base64 makes the script 4/3 larger.
+const InlineLimit = 4096
+
+// DefaultExpires is the default lifetime of presigned URLs.
+// The URL must be valid until the container starts, so it should cover pulling images.
+const DefaultExpires = time.Hour
+
+// argv0 is $0 of the wrapper shell.
+const argv0 = "ecs-task-script"
+
+type S3Client interface {
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+}
+
+type Presigner interface {
+	PresignGetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.PresignOptions)) (*v4.PresignedHTTPRequest, error)
+}
+
+// Script is a local script file.
+type Script struct {
+	Name string
+	Body []byte
+}
+
+// Load reads the script file.
+func Load(path string) (*Script, error) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to read script")
+	}
+	if len(body) == 0 {
+		return nil, errors.Errorf("Script is empty: %s", path)
+	}
+	return &Script{
+		Name: filepath.Base(path),
+		Body: body,
+	}, nil
+}
+
+// Inline returns whether the script is small enough to embed in the command.
+func (s *Script) Inline() bool {
+	return len(s.Body) <= InlineLimit
+}
+
+// InlineCommand returns a command which decodes the embedded script and executes it with args.
+func (s *Script) InlineCommand(args []string) []string {
+	encoded := base64.StdEncoding.EncodeToString(s.Body)
+	wrapper := `f=$(mktemp) && echo ` + encoded + ` | base64 -d > "$f" && chmod +x "$f" && exec "$f" "$@"`
+	return append([]string{"sh", "-c", wrapper, argv0}, args...)
+}
+
+// Key returns the object key of the script under the prefix.
+// The key contains the hash of the script, so the same script is uploaded to the same key.
+func (s *Script) Key(prefix string) string {
+	sum := sha256.Sum256(s.Body)
+	return prefix + hex.EncodeToString(sum[:]) + "/" + s.Name
+}
+
+// Uploader uploads scripts to a S3 bucket.
+type Uploader struct {
+	awsS3     S3Client
+	presigner Presigner
+	Bucket    string
+	Prefix    string
+	// Expires is the lifetime of presigned URLs.
+	Expires time.Duration
+}
+
+// NewUploader returns a new Uploader struct.
+// If bucket is empty, only scripts which are small enough to embed in the command are accepted.
+func NewUploader(awsS3 *s3.Client, bucket, prefix string) *Uploader {
+	return &Uploader{
+		awsS3:     awsS3,
+		presigner: s3.NewPresignClient(awsS3),
+		Bucket:    bucket,
+		Prefix:    prefix,
+		Expires:   DefaultExpires,
+	}
+}
+
+// Command returns a command which runs the script with args in a container.
+// A script which is larger than InlineLimit is uploaded to the bucket.
+func (u *Uploader) Command(ctx context.Context, s *Script, args []string) ([]string, error) {
+	if s.Inline() {
+		return s.InlineCommand(args), nil
+	}
+	if u.Bucket == "" {
+		return nil, errors.Errorf("Script is larger than %d bytes, so script bucket is required", InlineLimit)
+	}
+	url, err := u.Upload(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	wrapper := fmt.Sprintf(`f=$(mktemp) && { curl -fsSL -o "$f" %s || wget -q -O "$f" %s; } && chmod +x "$f" && exec "$f" "$@"`, quote(url), quote(url))
+	return append([]string{"sh", "-c", wrapper, argv0}, args...), nil
+}
+
+// Upload puts the script to the bucket, and returns a presigned URL to download it.
+func (u *Uploader) Upload(ctx context.Context, s *Script) (string, error) {
+	key := s.Key(u.Prefix)
+	_, err := u.awsS3.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(u.Bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(s.Body),
+	}, func(o *s3.Options) {
+		// The script can have secrets, so it is not written even if bodies are logged for debugging.
+		o.ClientLogMode &^= aws.LogRequestWithBody
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to upload script")
+	}
+	log.Infof("Script is uploaded to s3://%s/%s", u.Bucket, key)
+
+	req, err := u.presigner.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(u.Bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(u.Expires))
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to presign script URL")
+	}
+	return req.URL, nil
+}
+
+// quote quotes s with single quotes for shell.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
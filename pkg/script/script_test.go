@@ -0,0 +1,132 @@
+package script
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+type mockedS3 struct {
+	S3Client
+	objects map[string][]byte
+	logMode aws.ClientLogMode
+}
+
+func (m *mockedS3) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	body, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+	m.objects[*params.Bucket+"/"+*params.Key] = body
+	o := s3.Options{ClientLogMode: aws.LogRequestWithBody | aws.LogResponseWithBody}
+	for _, fn := range optFns {
+		fn(&o)
+	}
+	m.logMode = o.ClientLogMode
+	return &s3.PutObjectOutput{}, nil
+}
+
+type mockedPresigner struct{}
+
+func (m *mockedPresigner) PresignGetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.PresignOptions)) (*v4.PresignedHTTPRequest, error) {
+	return &v4.PresignedHTTPRequest{
+		URL: "https://" + *params.Bucket + ".s3.amazonaws.com/" + *params.Key + "?X-Amz-Signature=abc&X-Amz-Expires=3600",
+	}, nil
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "migrate.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\necho ok\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Name != "migrate.sh" {
+		t.Errorf("Name is invalid: %s", s.Name)
+	}
+
+	empty := filepath.Join(dir, "empty.sh")
+	if err := os.WriteFile(empty, []byte{}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(empty); err == nil {
+		t.Error("Empty script should be an error")
+	}
+}
+
+func TestInlineCommand(t *testing.T) {
+	s := &Script{Name: "hello.sh", Body: []byte("#!/bin/sh\necho \"hello $1\"\n")}
+	command := s.InlineCommand([]string{"world"})
+	if len(command) != 5 {
+		t.Fatalf("Command is invalid: %v", command)
+	}
+	if command[0] != "sh" || command[1] != "-c" || command[3] != argv0 || command[4] != "world" {
+		t.Errorf("Command is invalid: %v", command)
+	}
+	if !strings.Contains(command[2], base64.StdEncoding.EncodeToString(s.Body)) {
+		t.Errorf("Wrapper does not contain the script: %s", command[2])
+	}
+}
+
+func TestCommand(t *testing.T) {
+	awsS3 := &mockedS3{objects: map[string][]byte{}}
+	u := &Uploader{
+		awsS3:     awsS3,
+		presigner: &mockedPresigner{},
+		Bucket:    "scripts",
+		Prefix:    "ecs-task/",
+		Expires:   DefaultExpires,
+	}
+	ctx := context.Background()
+
+	small := &Script{Name: "small.sh", Body: []byte("#!/bin/sh\necho small\n")}
+	command, err := u.Command(ctx, small, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(awsS3.objects) != 0 {
+		t.Errorf("Small script should not be uploaded: %v", awsS3.objects)
+	}
+	if !strings.Contains(command[2], "base64 -d") {
+		t.Errorf("Small script should be inlined: %s", command[2])
+	}
+
+	large := &Script{Name: "large.sh", Body: []byte("#!/bin/sh\n" + strings.Repeat("echo large\n", 1000))}
+	command, err = u.Command(ctx, large, []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := large.Key("ecs-task/")
+	if string(awsS3.objects["scripts/"+key]) != string(large.Body) {
+		t.Errorf("Large script is not uploaded to %s", key)
+	}
+	if awsS3.logMode.IsRequestWithBody() {
+		t.Error("Body of the script is logged")
+	}
+	if !strings.HasPrefix(key, "ecs-task/") || !strings.HasSuffix(key, "/large.sh") {
+		t.Errorf("Key is invalid: %s", key)
+	}
+	url := "'https://scripts.s3.amazonaws.com/" + key + "?X-Amz-Signature=abc&X-Amz-Expires=3600'"
+	if !strings.Contains(command[2], url) {
+		t.Errorf("Wrapper does not download %s: %s", url, command[2])
+	}
+	if strings.Join(command[3:], " ") != argv0+" a b" {
+		t.Errorf("Arguments are invalid: %v", command[3:])
+	}
+
+	u.Bucket = ""
+	if _, err := u.Command(ctx, large, nil); err == nil {
+		t.Error("Large script without bucket should be an error")
+	}
+}
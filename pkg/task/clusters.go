@@ -0,0 +1,129 @@
+package task
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	ecstypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type ClustersClient interface {
+	ListClusters(ctx context.Context, params *ecs.ListClustersInput, optFns ...func(*ecs.Options)) (*ecs.ListClustersOutput, error)
+	DescribeClusters(ctx context.Context, params *ecs.DescribeClustersInput, optFns ...func(*ecs.Options)) (*ecs.DescribeClustersOutput, error)
+}
+
+// ClusterNames returns names of all ECS clusters.
+func ClusterNames(ctx context.Context, awsECS ClustersClient) ([]string, error) {
+	arns, err := clusterArns(ctx, awsECS)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, arn := range arns {
+		// Cluster ARN format is `arn:aws:ecs:<region>:<aws_account_id>:cluster/<cluster_name>`.
+		names = append(names, arn[strings.LastIndex(arn, "/")+1:])
+	}
+	return names, nil
+}
+
+func clusterArns(ctx context.Context, awsECS ClustersClient) ([]string, error) {
+	arns := []string{}
+	paginator := ecs.NewListClustersPaginator(awsECS, &ecs.ListClustersInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		arns = append(arns, output.ClusterArns...)
+	}
+	return arns, nil
+}
+
+// ClustersByTag returns names of ECS clusters which have the tag.
+// If value is empty, clusters which have the tag key are returned regardless of the value.
+func ClustersByTag(ctx context.Context, awsECS ClustersClient, key, value string) ([]string, error) {
+	arns, err := clusterArns(ctx, awsECS)
+	if err != nil {
+		return nil, err
+	}
+
+	clusters := []string{}
+	// DescribeClusters accepts up to 100 clusters at once.
+	for i := 0; i < len(arns); i += 100 {
+		end := i + 100
+		if end > len(arns) {
+			end = len(arns)
+		}
+		output, err := awsECS.DescribeClusters(ctx, &ecs.DescribeClustersInput{
+			Clusters: arns[i:end],
+			Include:  []ecstypes.ClusterField{ecstypes.ClusterFieldTags},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range output.Clusters {
+			if hasTag(c.Tags, key, value) {
+				clusters = append(clusters, *c.ClusterName)
+			}
+		}
+	}
+	return clusters, nil
+}
+
+func hasTag(tags []ecstypes.Tag, key, value string) bool {
+	for _, tag := range tags {
+		if tag.Key == nil || *tag.Key != key {
+			continue
+		}
+		if value == "" || (tag.Value != nil && *tag.Value == value) {
+			return true
+		}
+	}
+	return false
+}
+
+// RunClusters runs the same task on each cluster in parallel, and waits for all of them.
+// Results are returned in the same order as clusters, even if some of them are failed.
+// Logs of each task are prefixed with the cluster name.
+// If ecs-task receives an interrupt signal, all tasks are stopped.
+func (t *Task) RunClusters(clusters []string) ([]*TaskResult, error) {
+	if len(clusters) == 0 {
+		return nil, errors.New("Cluster name is required")
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	results := make([]*TaskResult, len(clusters))
+	var wg sync.WaitGroup
+	for i, cluster := range clusters {
+		wg.Add(1)
+		go func(i int, cluster string) {
+			defer wg.Done()
+			result, err := t.RunWith(ctx, &RunInput{
+				Cluster:   cluster,
+				LogPrefix: "[" + cluster + "] ",
+			})
+			if err != nil {
+				log.WithFields(log.Fields{"cluster": cluster}).Errorf("Run task is failed: %v", err)
+			}
+			results[i] = result
+		}(i, cluster)
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, result := range results {
+		if !result.Success {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return results, errors.Errorf("Run task is failed on %d of %d clusters", failed, len(clusters))
+	}
+	return results, nil
+}
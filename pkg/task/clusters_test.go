@@ -0,0 +1,110 @@
+package task
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	ecstypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+type mockedClusters struct {
+	ClustersClient
+	List     ecs.ListClustersOutput
+	Describe ecs.DescribeClustersOutput
+}
+
+func (m mockedClusters) ListClusters(ctx context.Context, params *ecs.ListClustersInput, optFns ...func(*ecs.Options)) (*ecs.ListClustersOutput, error) {
+	return &m.List, nil
+}
+
+func (m mockedClusters) DescribeClusters(ctx context.Context, params *ecs.DescribeClustersInput, optFns ...func(*ecs.Options)) (*ecs.DescribeClustersOutput, error) {
+	return &m.Describe, nil
+}
+
+func TestClustersByTag(t *testing.T) {
+	client := mockedClusters{
+		List: ecs.ListClustersOutput{
+			ClusterArns: []string{"arn-a", "arn-b", "arn-c"},
+		},
+		Describe: ecs.DescribeClustersOutput{
+			Clusters: []ecstypes.Cluster{
+				{
+					ClusterName: aws.String("cluster-a"),
+					Tags: []ecstypes.Tag{
+						{Key: aws.String("env"), Value: aws.String("production")},
+					},
+				},
+				{
+					ClusterName: aws.String("cluster-b"),
+					Tags: []ecstypes.Tag{
+						{Key: aws.String("env"), Value: aws.String("staging")},
+					},
+				},
+				{
+					ClusterName: aws.String("cluster-c"),
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		key      string
+		value    string
+		expected []string
+	}{
+		{
+			name:     "KeyAndValue",
+			key:      "env",
+			value:    "production",
+			expected: []string{"cluster-a"},
+		},
+		{
+			name:     "OnlyKey",
+			key:      "env",
+			expected: []string{"cluster-a", "cluster-b"},
+		},
+		{
+			name:     "NotMatched",
+			key:      "team",
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusters, err := ClustersByTag(context.Background(), client, tt.key, tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(clusters) != len(tt.expected) {
+				t.Fatalf("Clusters are invalid: %v", clusters)
+			}
+			for i := range clusters {
+				if clusters[i] != tt.expected[i] {
+					t.Errorf("Clusters are invalid: %v", clusters)
+				}
+			}
+		})
+	}
+}
+
+func TestClusterNames(t *testing.T) {
+	client := mockedClusters{
+		List: ecs.ListClustersOutput{
+			ClusterArns: []string{
+				"arn:aws:ecs:ap-northeast-1:1234567890:cluster/cluster-a",
+				"arn:aws:ecs:ap-northeast-1:1234567890:cluster/cluster-b",
+			},
+		},
+	}
+	names, err := ClusterNames(context.Background(), client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 || names[0] != "cluster-a" || names[1] != "cluster-b" {
+		t.Errorf("Cluster names are invalid: %v", names)
+	}
+}
@@ -8,10 +8,25 @@ import (
 	"github.com/aws/aws-sdk-go-v2/config"
 )
 
-// newConfig returns a new aws ConfigProvider
-func newConfig(profile string, region string) (aws.Config, error) {
-	return config.LoadDefaultConfig(context.Background(), config.WithRegion(region), config.WithSharedConfigProfile(profile))
+// NewConfig returns a new aws ConfigProvider.
+// It is exported so that other AWS clients can be created in the same manner as Task.
+// optFns are applied after profile and region, such as EndpointOptions.
+func NewConfig(profile string, region string, optFns ...func(*config.LoadOptions) error) (aws.Config, error) {
+	fns := []func(*config.LoadOptions) error{config.WithRegion(region), config.WithSharedConfigProfile(profile)}
+	return config.LoadDefaultConfig(context.Background(), append(fns, optFns...)...)
+}
 
+// EndpointOptions returns options of NewConfig to use FIPS and/or dual-stack (IPv6) endpoints for all clients.
+// If a flag is false, the endpoint follows the shared config and environment variables.
+func EndpointOptions(fips, dualStack bool) []func(*config.LoadOptions) error {
+	fns := []func(*config.LoadOptions) error{}
+	if fips {
+		fns = append(fns, config.WithUseFIPSEndpoint(aws.FIPSEndpointStateEnabled))
+	}
+	if dualStack {
+		fns = append(fns, config.WithUseDualStackEndpoint(aws.DualStackEndpointStateEnabled))
+	}
+	return fns
 }
 
 func getenv(value, key string) string {
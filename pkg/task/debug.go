@@ -0,0 +1,74 @@
+package task
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/smithy-go/logging"
+)
+
+// debugLogMode logs requests and responses with bodies, and retries.
+const debugLogMode = aws.LogRequestWithBody | aws.LogResponseWithBody | aws.LogRetries
+
+// redactedPatterns match credentials in HTTP messages. The first group is kept, and the rest is redacted.
+var redactedPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?im)^(Authorization:\s*).*$`),
+	regexp.MustCompile(`(?im)^(X-Amz-Security-Token:\s*).*$`),
+	regexp.MustCompile(`(?i)(X-Amz-(?:Credential|Security-Token|Signature)=)[^&\s]+`),
+	// Values of environment variables in task definitions and overrides, such as {"name":"DB_PASSWORD","value":"..."}.
+	regexp.MustCompile(`("name"\s*:\s*"(?:[^"\\]|\\.)*"\s*,\s*"value"\s*:\s*")(?:[^"\\]|\\.)*`),
+}
+
+// WithDebugLogging writes requests and responses of ECS and CloudWatch Logs API calls to w.
+// Credentials and values of environment variables are redacted, but other fields of bodies are written as they are.
+func WithDebugLogging(w io.Writer) Option {
+	logger := &debugLogger{w: w}
+	return func(t *Task) {
+		t.ecsOptions = append(t.ecsOptions, func(o *ecs.Options) {
+			o.ClientLogMode = debugLogMode
+			o.Logger = logger
+		})
+		t.logsOptions = append(t.logsOptions, func(o *cloudwatchlogs.Options) {
+			o.ClientLogMode = debugLogMode
+			o.Logger = logger
+		})
+	}
+}
+
+// DebugLoggingOptions returns options of NewConfig to write requests and responses of all API calls to w.
+// They are redacted in the same manner as WithDebugLogging. Each call returns a new logger,
+// so share the options among configs which write to the same w, or lines of them can be interleaved.
+func DebugLoggingOptions(w io.Writer) []func(*config.LoadOptions) error {
+	return []func(*config.LoadOptions) error{
+		config.WithClientLogMode(debugLogMode),
+		config.WithLogger(&debugLogger{w: w}),
+	}
+}
+
+// debugLogger is a logger of AWS SDK which redacts credentials.
+type debugLogger struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *debugLogger) Logf(classification logging.Classification, format string, v ...interface{}) {
+	message := redact(fmt.Sprintf(format, v...))
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintf(l.w, "[aws] %s %s\n", classification, strings.TrimRight(message, "\r\n"))
+}
+
+// redact replaces credentials in the message with [REDACTED].
+func redact(message string) string {
+	for _, p := range redactedPatterns {
+		message = p.ReplaceAllString(message, "${1}[REDACTED]")
+	}
+	return message
+}
@@ -0,0 +1,40 @@
+package task
+
+import (
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+)
+
+// logsClients caches CloudWatch Logs clients for each region.
+type logsClients struct {
+	mu      sync.Mutex
+	clients map[string]*cloudwatchlogs.Client
+}
+
+// logsClient returns a CloudWatch Logs client for the region where the log group is.
+// If the region is empty or same as the config, the default client is returned.
+func (t *Task) logsClient(region string) *cloudwatchlogs.Client {
+	if region == "" || region == t.logsConfig.Region {
+		return t.awsLogs
+	}
+	newClient := func() *cloudwatchlogs.Client {
+		optFns := append([]func(*cloudwatchlogs.Options){}, t.logsOptions...)
+		optFns = append(optFns, func(o *cloudwatchlogs.Options) {
+			o.Region = region
+		})
+		return cloudwatchlogs.NewFromConfig(*t.logsConfig, optFns...)
+	}
+	if t.regionalLogs == nil {
+		return newClient()
+	}
+
+	t.regionalLogs.mu.Lock()
+	defer t.regionalLogs.mu.Unlock()
+	client, ok := t.regionalLogs.clients[region]
+	if !ok {
+		client = newClient()
+		t.regionalLogs.clients[region] = client
+	}
+	return client
+}
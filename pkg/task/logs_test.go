@@ -0,0 +1,30 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+)
+
+func TestLogsClient(t *testing.T) {
+	cfg := aws.Config{Region: "ap-northeast-1"}
+	task := &Task{
+		awsLogs:      cloudwatchlogs.NewFromConfig(cfg),
+		logsConfig:   &cfg,
+		regionalLogs: &logsClients{clients: map[string]*cloudwatchlogs.Client{}},
+	}
+	if task.logsClient("") != task.awsLogs || task.logsClient("ap-northeast-1") != task.awsLogs {
+		t.Error("Default client is not used for the same region")
+	}
+	client := task.logsClient("us-east-1")
+	if client == task.awsLogs {
+		t.Fatal("Default client is used for another region")
+	}
+	if client.Options().Region != "us-east-1" {
+		t.Errorf("Region is invalid: %s", client.Options().Region)
+	}
+	if task.logsClient("us-east-1") != client {
+		t.Error("Client is not cached")
+	}
+}
@@ -0,0 +1,171 @@
+package task
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	snstypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Event is an event of the run which is notified to Notifier.
+type Event string
+
+const (
+	// EventStart is notified when the task is started.
+	EventStart Event = "start"
+	// EventSuccess is notified when the task is succeeded.
+	EventSuccess Event = "success"
+	// EventFailure is notified when the run is failed.
+	EventFailure Event = "failure"
+)
+
+// Notifier is notified of the run. Task calls OnStart when the task is started,
+// and calls OnSuccess or OnFailure when the run is finished.
+// Errors which are returned from Notifier are only logged, and they do not affect the run.
+type Notifier interface {
+	OnStart(ctx context.Context, result *TaskResult) error
+	OnSuccess(ctx context.Context, result *TaskResult) error
+	OnFailure(ctx context.Context, result *TaskResult) error
+}
+
+// notify calls all notifiers for the event.
+func (t *Task) notify(event Event, result *TaskResult) {
+	ctx := context.Background()
+	for _, n := range t.Notifiers {
+		var err error
+		switch event {
+		case EventStart:
+			err = n.OnStart(ctx, result)
+		case EventSuccess:
+			err = n.OnSuccess(ctx, result)
+		case EventFailure:
+			err = n.OnFailure(ctx, result)
+		}
+		if err != nil {
+			log.Errorf("Failed to notify %s event to %T: %v", event, n, err)
+		}
+	}
+}
+
+// SlackNotifier posts messages to Slack with Incoming Webhooks.
+type SlackNotifier struct {
+	URL    string
+	client *http.Client
+}
+
+// NewSlackNotifier returns a new SlackNotifier struct.
+func NewSlackNotifier(url string) *SlackNotifier {
+	return &SlackNotifier{
+		URL:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// OnStart posts a message when the task is started.
+func (s *SlackNotifier) OnStart(ctx context.Context, result *TaskResult) error {
+	return s.post(ctx, fmt.Sprintf(":rocket: Task `%s` is started on `%s`: `%s`", result.TaskID, result.Cluster, strings.Join(result.Command, " ")))
+}
+
+// OnSuccess posts a message when the task is succeeded.
+func (s *SlackNotifier) OnSuccess(ctx context.Context, result *TaskResult) error {
+	return s.post(ctx, fmt.Sprintf(":white_check_mark: Task `%s` is succeeded on `%s` in %s: `%s`", result.TaskID, result.Cluster, result.Duration.Round(time.Second), strings.Join(result.Command, " ")))
+}
+
+// OnFailure posts a message when the run is failed.
+func (s *SlackNotifier) OnFailure(ctx context.Context, result *TaskResult) error {
+	return s.post(ctx, fmt.Sprintf(":x: Task `%s` is failed on `%s` in %s: `%s`\n%s", result.TaskID, result.Cluster, result.Duration.Round(time.Second), strings.Join(result.Command, " "), result.Error))
+}
+
+func (s *SlackNotifier) post(ctx context.Context, text string) error {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := s.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("Slack returns status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+type SNSClient interface {
+	Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error)
+}
+
+// SNSNotifier publishes WebhookPayload as JSON to SNS topic.
+type SNSNotifier struct {
+	awsSNS   SNSClient
+	TopicArn string
+}
+
+// NewSNSNotifier returns a new SNSNotifier struct.
+func NewSNSNotifier(awsSNS SNSClient, topicArn string) *SNSNotifier {
+	return &SNSNotifier{
+		awsSNS:   awsSNS,
+		TopicArn: topicArn,
+	}
+}
+
+// OnStart publishes a message when the task is started.
+func (s *SNSNotifier) OnStart(ctx context.Context, result *TaskResult) error {
+	return s.publish(ctx, EventStart, result)
+}
+
+// OnSuccess publishes a message when the task is succeeded.
+func (s *SNSNotifier) OnSuccess(ctx context.Context, result *TaskResult) error {
+	return s.publish(ctx, EventSuccess, result)
+}
+
+// OnFailure publishes a message when the run is failed.
+func (s *SNSNotifier) OnFailure(ctx context.Context, result *TaskResult) error {
+	return s.publish(ctx, EventFailure, result)
+}
+
+func (s *SNSNotifier) publish(ctx context.Context, event Event, result *TaskResult) error {
+	message, err := json.Marshal(&WebhookPayload{
+		Event:  event,
+		Result: result,
+	})
+	if err != nil {
+		return err
+	}
+	subject := fmt.Sprintf("ecs-task %s: %s", event, result.Cluster)
+	// SNS subject must be less than 100 characters.
+	if len(subject) > 100 {
+		subject = subject[:100]
+	}
+	_, err = s.awsSNS.Publish(ctx, &sns.PublishInput{
+		TopicArn: aws.String(s.TopicArn),
+		Subject:  aws.String(subject),
+		Message:  aws.String(string(message)),
+		MessageAttributes: map[string]snstypes.MessageAttributeValue{
+			"event": {
+				DataType:    aws.String("String"),
+				StringValue: aws.String(string(event)),
+			},
+		},
+	})
+	return err
+}
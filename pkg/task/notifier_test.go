@@ -0,0 +1,95 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+)
+
+type recordingNotifier struct {
+	events []Event
+}
+
+func (r *recordingNotifier) OnStart(ctx context.Context, result *TaskResult) error {
+	r.events = append(r.events, EventStart)
+	return nil
+}
+
+func (r *recordingNotifier) OnSuccess(ctx context.Context, result *TaskResult) error {
+	r.events = append(r.events, EventSuccess)
+	return nil
+}
+
+func (r *recordingNotifier) OnFailure(ctx context.Context, result *TaskResult) error {
+	r.events = append(r.events, EventFailure)
+	return nil
+}
+
+func TestNotify(t *testing.T) {
+	n := &recordingNotifier{}
+	task := &Task{}
+	WithNotifier(n)(task)
+	task.notify(EventStart, &TaskResult{})
+	task.notify(EventFailure, &TaskResult{})
+	if len(n.events) != 2 || n.events[0] != EventStart || n.events[1] != EventFailure {
+		t.Errorf("Events are invalid: %v", n.events)
+	}
+}
+
+func TestSlackNotifier(t *testing.T) {
+	var body map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &body)
+	}))
+	defer server.Close()
+
+	slack := NewSlackNotifier(server.URL)
+	err := slack.OnFailure(context.Background(), &TaskResult{
+		Cluster: "cluster",
+		TaskID:  "task-id",
+		Command: []string{"echo", "hoge"},
+		Error:   "exit code: 1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(body["text"], "`task-id` is failed on `cluster`") || !strings.Contains(body["text"], "exit code: 1") {
+		t.Errorf("Message is invalid: %s", body["text"])
+	}
+}
+
+type mockedSNS struct {
+	SNSClient
+	input *sns.PublishInput
+}
+
+func (m *mockedSNS) Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error) {
+	m.input = params
+	return &sns.PublishOutput{}, nil
+}
+
+func TestSNSNotifier(t *testing.T) {
+	client := &mockedSNS{}
+	notifier := NewSNSNotifier(client, "topic-arn")
+	err := notifier.OnSuccess(context.Background(), &TaskResult{Cluster: "cluster", Success: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *client.input.TopicArn != "topic-arn" {
+		t.Error("Topic ARN is invalid")
+	}
+	var payload WebhookPayload
+	if err := json.Unmarshal([]byte(*client.input.Message), &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Event != EventSuccess || !payload.Result.Success {
+		t.Errorf("Message is invalid: %s", *client.input.Message)
+	}
+}
@@ -0,0 +1,138 @@
+package task
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultPushgatewayJob is the default job label of metrics which are pushed to Pushgateway.
+const DefaultPushgatewayJob = "ecs-task"
+
+// Pushgateway pushes metrics of the run to Prometheus Pushgateway when the run is finished.
+// Metrics are grouped by job, cluster, family and command_hash, and each run replaces metrics of the previous run in the group.
+//
+// The following metrics are pushed.
+//
+//	ecs_task_duration_seconds: Duration of the run.
+//	ecs_task_exit_code: Exit code of the target container. It is omitted, if the container did not exit.
+//	ecs_task_success: 1 if the run is succeeded, otherwise 0.
+//	ecs_task_last_run_timestamp_seconds: Unix time when the run was finished.
+type Pushgateway struct {
+	// URL of Pushgateway, like http://pushgateway:9091.
+	URL    string
+	Job    string
+	client *http.Client
+}
+
+// NewPushgateway returns a new Pushgateway struct.
+// If job is empty, DefaultPushgatewayJob is used.
+func NewPushgateway(gatewayURL, job string) *Pushgateway {
+	if job == "" {
+		job = DefaultPushgatewayJob
+	}
+	return &Pushgateway{
+		URL:    strings.TrimRight(gatewayURL, "/"),
+		Job:    job,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Push replaces metrics of the group of the result with metrics of the result.
+func (p *Pushgateway) Push(ctx context.Context, result *TaskResult) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, p.groupURL(result), bytes.NewReader(p.metrics(result, time.Now())))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	client := p.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("Pushgateway returns status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// OnStart does nothing, because metrics are pushed when the run is finished.
+func (p *Pushgateway) OnStart(ctx context.Context, result *TaskResult) error {
+	return nil
+}
+
+// OnSuccess pushes metrics of the succeeded run.
+func (p *Pushgateway) OnSuccess(ctx context.Context, result *TaskResult) error {
+	return p.Push(ctx, result)
+}
+
+// OnFailure pushes metrics of the failed run.
+func (p *Pushgateway) OnFailure(ctx context.Context, result *TaskResult) error {
+	return p.Push(ctx, result)
+}
+
+// groupURL returns the URL of the group, like `<url>/metrics/job/<job>/cluster/<cluster>/family/<family>/command_hash/<hash>`.
+func (p *Pushgateway) groupURL(result *TaskResult) string {
+	labels := [][2]string{
+		{"job", p.Job},
+		{"cluster", result.Cluster},
+		{"family", Family(result.TaskDefinition)},
+		{"command_hash", commandHash(result.Command)},
+	}
+	u := p.URL + "/metrics"
+	for _, l := range labels {
+		u += "/" + groupingLabel(l[0], l[1])
+	}
+	return u
+}
+
+// metrics returns metrics of the result in Prometheus text format.
+func (p *Pushgateway) metrics(result *TaskResult, now time.Time) []byte {
+	var buf bytes.Buffer
+	gauge := func(name, help string, value float64) {
+		fmt.Fprintf(&buf, "# HELP %s %s\n# TYPE %s gauge\n%s %s\n", name, help, name, name, strconv.FormatFloat(value, 'f', -1, 64))
+	}
+	gauge("ecs_task_duration_seconds", "Duration of the run in seconds.", result.Duration.Seconds())
+	if result.ExitCode != nil {
+		gauge("ecs_task_exit_code", "Exit code of the target container.", float64(*result.ExitCode))
+	}
+	success := 0.0
+	if result.Success {
+		success = 1
+	}
+	gauge("ecs_task_success", "Whether the run is succeeded.", success)
+	gauge("ecs_task_last_run_timestamp_seconds", "Unix time when the run was finished.", float64(now.Unix()))
+	return buf.Bytes()
+}
+
+// groupingLabel returns a path segment of the grouping key.
+// Empty values and values which have "/" are encoded with base64 as Pushgateway requires.
+func groupingLabel(name, value string) string {
+	if value == "" {
+		return name + "@base64/="
+	}
+	if strings.Contains(value, "/") {
+		return name + "@base64/" + base64.RawURLEncoding.EncodeToString([]byte(value))
+	}
+	return name + "/" + url.PathEscape(value)
+}
+
+// commandHash returns a short hash of the command to distinguish runs of the same family.
+func commandHash(command []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(command, "\x00")))
+	return hex.EncodeToString(sum[:])[:12]
+}
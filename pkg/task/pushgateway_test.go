@@ -0,0 +1,84 @@
+package task
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+func TestPushgatewayPush(t *testing.T) {
+	var method, path, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		path = r.URL.EscapedPath()
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+	}))
+	defer server.Close()
+
+	p := NewPushgateway(server.URL+"/", "")
+	err := p.OnFailure(context.Background(), &TaskResult{
+		Cluster:        "default",
+		TaskDefinition: "arn:aws:ecs:ap-northeast-1:123456789012:task-definition/migrate:3",
+		Command:        []string{"rake", "db:migrate"},
+		ExitCode:       aws.Int32(1),
+		Duration:       90 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if method != http.MethodPut {
+		t.Errorf("Method is invalid: %s", method)
+	}
+	expectedPath := "/metrics/job/ecs-task/cluster/default/family/migrate/command_hash/" + commandHash([]string{"rake", "db:migrate"})
+	if path != expectedPath {
+		t.Errorf("Path is invalid: %s", path)
+	}
+	for _, expected := range []string{
+		"# TYPE ecs_task_duration_seconds gauge\necs_task_duration_seconds 90\n",
+		"ecs_task_exit_code 1\n",
+		"ecs_task_success 0\n",
+		"ecs_task_last_run_timestamp_seconds ",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Body does not contain %q: %s", expected, body)
+		}
+	}
+	if strings.Contains(body, "e+") {
+		t.Errorf("Body has exponent notation: %s", body)
+	}
+}
+
+func TestPushgatewayPushError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	if err := NewPushgateway(server.URL, "job").Push(context.Background(), &TaskResult{}); err == nil {
+		t.Error("Does not error when Pushgateway returns 400")
+	}
+}
+
+func TestGroupingLabel(t *testing.T) {
+	cases := []struct {
+		value    string
+		expected string
+	}{
+		{"default", "cluster/default"},
+		{"", "cluster@base64/="},
+		{"a/b", "cluster@base64/YS9i"},
+		{"a b", "cluster/a%20b"},
+	}
+	for _, c := range cases {
+		if label := groupingLabel("cluster", c.value); label != c.expected {
+			t.Errorf("Label of %q is invalid: %s", c.value, label)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package task
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+
+	ecstypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+// TaskResult has the result of a task execution.
+type TaskResult struct {
+	// ECS Cluster where the task was run.
+	Cluster string `json:"cluster"`
+	// Name of Task Definition which is provided to run the task.
+	TaskDefinition string `json:"taskDefinition"`
+	// Container name which the command was run.
+	Container string `json:"container"`
+	// Command which was run. Queries of presigned URLs in the command are redacted.
+	Command []string `json:"command"`
+	// Other parameters to run the task, like launchType and subnets. Empty parameters are omitted.
+	Parameters map[string]string `json:"parameters,omitempty"`
+	TaskArn    string            `json:"taskArn,omitempty"`
+	TaskID     string            `json:"taskId,omitempty"`
+	// Exit code of the container. It is nil, if the container did not exit.
+	ExitCode      *int32     `json:"exitCode"`
+	StoppedReason string     `json:"stoppedReason,omitempty"`
+	StartedAt     *time.Time `json:"startedAt,omitempty"`
+	StoppedAt     *time.Time `json:"stoppedAt,omitempty"`
+	// Duration from when the run was requested to when the task was stopped.
+	Duration time.Duration `json:"-"`
+	Success  bool          `json:"success"`
+	// Why the task was stopped, such as EssentialContainerExited.
+	StopCode string `json:"stopCode,omitempty"`
+	// Results of all containers in the task.
+	Containers []ContainerResult `json:"containers,omitempty"`
+	// Essential container which stopped the task, when the task was stopped with failure.
+	// It can be different from the target container, for example when a sidecar container is killed by OOM.
+	CausedBy string `json:"causedBy,omitempty"`
+	// Error message when the run was failed.
+	Error string `json:"error,omitempty"`
+}
+
+type taskResultJSON TaskResult
+
+// MarshalJSON encodes the result with duration in seconds.
+func (r TaskResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		taskResultJSON
+		DurationSeconds float64 `json:"durationSeconds"`
+	}{taskResultJSON(r), r.Duration.Seconds()})
+}
+
+// UnmarshalJSON decodes the result which is encoded by MarshalJSON.
+func (r *TaskResult) UnmarshalJSON(data []byte) error {
+	v := struct {
+		*taskResultJSON
+		DurationSeconds float64 `json:"durationSeconds"`
+	}{taskResultJSON: (*taskResultJSON)(r)}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	r.Duration = time.Duration(v.DurationSeconds * float64(time.Second))
+	return nil
+}
+
+// newTaskResult returns a TaskResult which has parameters of the run.
+func (t *Task) newTaskResult(in *RunInput) *TaskResult {
+	parameters := map[string]string{
+		"launchType":      string(t.LaunchType),
+		"subnets":         strings.Join(t.Subnets, ","),
+		"securityGroups":  strings.Join(t.SecurityGroups, ","),
+		"platformVersion": t.PlatformVersion,
+		"taskSizeCpu":     in.TaskSizeCpu,
+		"taskSizeMemory":  in.TaskSizeMemory,
+	}
+	if t.Timeout > 0 {
+		parameters["timeout"] = t.Timeout.String()
+	}
+	for k, v := range parameters {
+		if v == "" {
+			delete(parameters, k)
+		}
+	}
+	return &TaskResult{
+		Cluster:        in.Cluster,
+		TaskDefinition: in.TaskDefinitionName,
+		Container:      in.Container,
+		Command:        RedactCommand(in.Command),
+		Parameters:     parameters,
+	}
+}
+
+// presignedQuery matches a query of presigned URLs, which has temporary credentials.
+var presignedQuery = regexp.MustCompile(`\?[^\s'"]*X-Amz-(?:Credential|Signature|Security-Token)=[^\s'"]*`)
+
+// RedactCommand returns a copy of the command whose presigned URLs are replaced with the URLs without queries,
+// so that results and logs do not have the credentials, such as a command which downloads a script.
+func RedactCommand(command []string) []string {
+	if command == nil {
+		return nil
+	}
+	redacted := make([]string, len(command))
+	for i, arg := range command {
+		redacted[i] = presignedQuery.ReplaceAllString(arg, "?[REDACTED]")
+	}
+	return redacted
+}
+
+// setTask sets the task information to the result.
+// The task definition is used to know essential containers, and it can be nil.
+func (r *TaskResult) setTask(task *ecstypes.Task, container string, taskDef *ecstypes.TaskDefinition) {
+	if task == nil {
+		return
+	}
+	if task.TaskArn != nil {
+		r.TaskArn = *task.TaskArn
+	}
+	if task.StoppedReason != nil {
+		r.StoppedReason = *task.StoppedReason
+	}
+	r.StartedAt = task.StartedAt
+	r.StoppedAt = task.StoppedAt
+	for _, c := range task.Containers {
+		if c.Name != nil && *c.Name == container {
+			r.ExitCode = c.ExitCode
+		}
+	}
+	r.StopCode = string(task.StopCode)
+	r.Containers = containerResults(task, taskDef)
+	r.CausedBy = ""
+	if task.LastStatus != nil && *task.LastStatus == "STOPPED" && (r.ExitCode == nil || *r.ExitCode != 0) {
+		if c := stoppedBy(r.Containers, container); c != nil {
+			r.CausedBy = c.Name
+		}
+	}
+}
+
+// finish sets the outcome of the run to the result.
+func (r *TaskResult) finish(started time.Time, err error) {
+	r.Duration = time.Since(started)
+	r.Success = err == nil
+	if err != nil {
+		r.Error = err.Error()
+	}
+}
@@ -2,10 +2,11 @@ package task
 
 import (
 	"context"
-	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"regexp"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -16,53 +17,91 @@ import (
 
 // Run a command on AWS ECS and output the log.
 func (t *Task) Run() error {
-	ctx := context.Background()
-	taskDef, err := t.taskDefinition.DescribeTaskDefinition(ctx, t.TaskDefinitionName)
+	_, err := t.RunWithResult()
+	return err
+}
+
+// RunWithResult runs a command on AWS ECS and output the log, and returns the result of the task.
+// The result is returned even if the run is failed. If ecs-task receives an interrupt signal, the task is stopped.
+func (t *Task) RunWithResult() (*TaskResult, error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return t.RunWith(ctx, nil)
+}
+
+// RunWith runs a task with the parameters of the input, and returns the result of the task.
+// Empty fields of the input are filled with fields of Task, so input can be nil.
+// It is safe to call RunWith concurrently. If ctx is canceled, the task is stopped.
+func (t *Task) RunWith(ctx context.Context, input *RunInput) (*TaskResult, error) {
+	in := t.resolve(input)
+	result := t.newTaskResult(in)
+	started := time.Now()
+	err := t.run(ctx, result, in)
+	result.finish(started, err)
+	if err != nil {
+		t.notify(EventFailure, result)
+	} else {
+		t.notify(EventSuccess, result)
+	}
+	return result, err
+}
+
+func (t *Task) run(parent context.Context, result *TaskResult, in *RunInput) error {
+	taskDef, err := t.taskDefinition.DescribeTaskDefinition(parent, in.TaskDefinitionName)
 	if err != nil {
 		return err
 	}
-	group, streamPrefix, err := t.taskDefinition.GetLogGroup(taskDef, t.Container)
+	logs, err := t.containerLogs(taskDef, in)
 	if err != nil {
 		return err
 	}
-	ctx, cancel := context.WithCancel(context.Background())
+	// Pollers keep running after parent is canceled, until the task is stopped.
+	ctx, cancel := context.WithCancel(context.WithoutCancel(parent))
 	defer cancel()
 
-	sigchan := make(chan os.Signal, 1)
-	signal.Notify(sigchan, os.Interrupt)
-
-	task, err := t.RunTask(ctx, taskDef)
+	task, err := t.runTask(parent, taskDef, in)
 	if err != nil {
 		return err
 	}
 
 	taskID := t.buildLogStream(task)
-	logPollDoneChan := make(chan struct{})
+	result.setTask(task, in.Container, taskDef)
+	result.TaskID = taskID
+	t.notify(EventStart, result)
+	var logPollWait sync.WaitGroup
 	pollLogsCtx, pollLogsCancel := context.WithCancel(ctx)
-	w := NewWatcher(group, streamPrefix+"/"+t.Container+"/"+taskID, t.awsLogs, t.timestampFormat)
-	go func() {
-		defer close(logPollDoneChan)
-		log.Info("Polling logs")
-		err := w.Polling(pollLogsCtx)
-		if err != nil {
-			log.Errorf("Get logs thread failed: %v", err)
-		} else {
-			log.Info("Get logs thread gracefully stopping")
-		}
-	}()
+	for _, l := range logs {
+		w := NewWatcher(l.group, l.streamPrefix+"/"+l.container+"/"+taskID, t.logsClient(l.region), t.timestampFormat)
+		w.Format = t.LogFormat
+		w.Fields = t.LogFields
+		w.Color = t.LogColor
+		w.Prefix = in.LogPrefix
+		w.Writer = l.writer
+		logPollWait.Add(1)
+		go func(container string) {
+			defer logPollWait.Done()
+			log.WithFields(log.Fields{"container": container}).Info("Polling logs")
+			err := w.Polling(pollLogsCtx)
+			if err != nil {
+				log.Errorf("Get logs thread failed: %v", err)
+			} else {
+				log.Info("Get logs thread gracefully stopping")
+			}
+		}(l.container)
+	}
 
-	pollTaskStopDoneChan := make(chan error)
+	pollTaskStopDoneChan := make(chan stoppedTask, 1)
 	pollExitCtx, pollExitCancel := context.WithCancel(ctx)
 	defer pollExitCancel() // make go vet lostcancel happy
 	go func() {
 		defer close(pollTaskStopDoneChan)
-		err := t.WaitTask(pollExitCtx, task)
+		stopped, err := t.waitTask(pollExitCtx, task, in, taskDef)
 		if err != nil {
 			log.Errorf("Task status polling thread failed: %v", err)
 		} else {
 			log.Info("Task status polling thread gracefully stopping")
 		}
-		pollTaskStopDoneChan <- err
+		pollTaskStopDoneChan <- stoppedTask{stopped, err}
 	}()
 
 	var timeoutChan <-chan time.Time
@@ -72,12 +111,13 @@ func (t *Task) Run() error {
 
 	var stopTaskReason string
 	select {
-	case sig := <-sigchan:
-		log.WithFields(log.Fields{
-			"signal": sig.String(),
-		}).Info("Received signal; calling ecs.StopTask on task")
-		stopTaskReason = fmt.Sprintf("ecs-task propagating signal %s", sig.String())
-	case err = <-pollTaskStopDoneChan:
+	case <-parent.Done():
+		log.Info("Context is canceled; calling ecs.StopTask on task")
+		stopTaskReason = "ecs-task context canceled"
+		err = parent.Err()
+	case stopped := <-pollTaskStopDoneChan:
+		err = stopped.err
+		result.setTask(stopped.task, in.Container, taskDef)
 		log.Info("Task stopped on its own")
 	case <-timeoutChan:
 		log.WithFields(log.Fields{
@@ -86,7 +126,7 @@ func (t *Task) Run() error {
 	}
 	if stopTaskReason != "" {
 		params := ecs.StopTaskInput{
-			Cluster: aws.String(t.Cluster),
+			Cluster: aws.String(in.Cluster),
 			Reason:  aws.String(stopTaskReason),
 			Task:    task.TaskArn,
 		}
@@ -99,20 +139,71 @@ func (t *Task) Run() error {
 		case <-time.After(60 * time.Second):
 			log.Info("Task is still not done after 60s; giving up on checking its status")
 			pollExitCancel()
-			err = <-pollTaskStopDoneChan
-		case err = <-pollTaskStopDoneChan:
+			if stopped := <-pollTaskStopDoneChan; err == nil {
+				err = stopped.err
+			}
+		case stopped := <-pollTaskStopDoneChan:
+			if err == nil {
+				err = stopped.err
+			}
+			result.setTask(stopped.task, in.Container, taskDef)
 		}
 	}
 
 	log.Info("Waiting 10s for more GetLogEvents")
-	time.Sleep(10 * time.Second)
+	select {
+	case <-time.After(10 * time.Second):
+	case <-parent.Done():
+	}
 	log.Info("Shutting down get logs thread")
 	pollLogsCancel()
-	<-logPollDoneChan
+	logPollWait.Wait()
 	log.Info("Exiting")
 	return err
 }
 
+// containerLog has log configuration of a container which is watched.
+type containerLog struct {
+	container    string
+	group        string
+	streamPrefix string
+	region       string
+	writer       io.Writer
+}
+
+// containerLogs returns log configurations of the target container and containers in ContainerWriters.
+// The target container must use awslogs, but other containers which do not use awslogs are ignored.
+func (t *Task) containerLogs(taskDef *ecstypes.TaskDefinition, in *RunInput) ([]containerLog, error) {
+	c, err := t.taskDefinition.GetLogConfiguration(taskDef, in.Container)
+	if err != nil {
+		return nil, err
+	}
+	writer := in.LogWriter
+	if w, ok := in.ContainerWriters[in.Container]; ok {
+		writer = w
+	}
+	logs := []containerLog{{in.Container, c.Group, c.StreamPrefix, c.Region, writer}}
+
+	for container, writer := range in.ContainerWriters {
+		if container == in.Container {
+			continue
+		}
+		c, err := t.taskDefinition.GetLogConfiguration(taskDef, container)
+		if err != nil {
+			log.Warnf("Can not watch logs of container %s: %v", container, err)
+			continue
+		}
+		logs = append(logs, containerLog{container, c.Group, c.StreamPrefix, c.Region, writer})
+	}
+	return logs, nil
+}
+
+// stoppedTask is sent from the task status polling thread.
+type stoppedTask struct {
+	task *ecstypes.Task
+	err  error
+}
+
 // buildLogStream returns a CloudWatchLog Stream name from ECS task.
 // Task ARN format is `arn:aws:ecs:<region>:<aws_account_id>:task(/<cluster_name>)/c5cba4eb-5dad-405e-96db-71ef8eefe6a8`.
 // And Log Stream format is `stream_prefix/container_name/task_id`.
@@ -1,6 +1,8 @@
 package task
 
 import (
+	"bytes"
+	"io"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -38,3 +40,45 @@ func TestBuildLogStream(t *testing.T) {
 		})
 	}
 }
+
+func TestContainerLogs(t *testing.T) {
+	logConfiguration := func(group string) *ecstypes.LogConfiguration {
+		return &ecstypes.LogConfiguration{
+			LogDriver: ecstypes.LogDriverAwslogs,
+			Options: map[string]string{
+				"awslogs-group":         group,
+				"awslogs-stream-prefix": "prefix",
+			},
+		}
+	}
+	taskDef := &ecstypes.TaskDefinition{
+		ContainerDefinitions: []ecstypes.ContainerDefinition{
+			{Name: aws.String("target"), LogConfiguration: logConfiguration("target-group")},
+			{Name: aws.String("sidecar"), LogConfiguration: logConfiguration("sidecar-group")},
+			{Name: aws.String("nolog")},
+		},
+	}
+	var target, sidecar bytes.Buffer
+	task := &Task{
+		Container:      "target",
+		taskDefinition: &TaskDefinition{},
+		LogWriter:      &target,
+		ContainerWriters: map[string]io.Writer{
+			"sidecar": &sidecar,
+			"nolog":   &sidecar,
+		},
+	}
+	logs, err := task.containerLogs(taskDef, task.resolve(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 2 {
+		t.Fatalf("Logs are invalid: %+v", logs)
+	}
+	if logs[0].container != "target" || logs[0].group != "target-group" || logs[0].writer != &target {
+		t.Errorf("Log of target container is invalid: %+v", logs[0])
+	}
+	if logs[1].container != "sidecar" || logs[1].group != "sidecar-group" || logs[1].writer != &sidecar {
+		t.Errorf("Log of sidecar container is invalid: %+v", logs[1])
+	}
+}
@@ -0,0 +1,138 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+
+	ecstypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/pkg/errors"
+)
+
+// ContainerResult has the result of a container in the task.
+type ContainerResult struct {
+	Name string `json:"name"`
+	// Exit code of the container. It is nil, if the container did not exit, or did not start.
+	ExitCode *int32 `json:"exitCode"`
+	// Reason why the container stopped, such as OutOfMemoryError.
+	Reason string `json:"reason,omitempty"`
+	// If an essential container stops, all other containers in the task are stopped.
+	Essential bool `json:"essential"`
+}
+
+// failed returns whether the container did not exit with 0.
+func (c *ContainerResult) failed() bool {
+	return c.ExitCode == nil || *c.ExitCode != 0
+}
+
+// stoppedByECS returns whether the container looks like to be killed by ECS, after another essential container stopped.
+func (c *ContainerResult) stoppedByECS() bool {
+	return c.Reason == "" && (c.ExitCode == nil || *c.ExitCode == 137 || *c.ExitCode == 143)
+}
+
+func (c *ContainerResult) String() string {
+	s := "exit code: " + exitCodeString(c.ExitCode)
+	if c.Reason != "" {
+		s += ", reason: " + c.Reason
+	}
+	return s
+}
+
+// containerResults returns results of containers in the task.
+// Containers are essential unless the task definition says otherwise. If taskDef is nil, all containers are essential.
+func containerResults(task *ecstypes.Task, taskDef *ecstypes.TaskDefinition) []ContainerResult {
+	nonEssential := map[string]bool{}
+	if taskDef != nil {
+		for _, d := range taskDef.ContainerDefinitions {
+			if d.Name != nil && d.Essential != nil && !*d.Essential {
+				nonEssential[*d.Name] = true
+			}
+		}
+	}
+	results := []ContainerResult{}
+	for _, c := range task.Containers {
+		if c.Name == nil {
+			continue
+		}
+		result := ContainerResult{
+			Name:      *c.Name,
+			ExitCode:  c.ExitCode,
+			Essential: !nonEssential[*c.Name],
+		}
+		if c.Reason != nil {
+			result.Reason = *c.Reason
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// stoppedBy returns the essential container which is most likely to have stopped the task, or nil if all essential containers succeeded.
+// ECS does not tell which container exited first, so a container which has a reason such as OutOfMemoryError is preferred,
+// and containers which look like to be killed by ECS come last. The target container is preferred among equals.
+func stoppedBy(containers []ContainerResult, target string) *ContainerResult {
+	rank := func(c *ContainerResult) int {
+		switch {
+		case c.Reason != "":
+			return 0
+		case !c.stoppedByECS():
+			return 1
+		default:
+			return 2
+		}
+	}
+	var cause *ContainerResult
+	for i := range containers {
+		c := &containers[i]
+		if !c.Essential || !c.failed() {
+			continue
+		}
+		if cause == nil || rank(c) < rank(cause) || (rank(c) == rank(cause) && c.Name == target) {
+			cause = c
+		}
+	}
+	return cause
+}
+
+// stoppedError returns an error which describes why the task stopped without success of the target container.
+func stoppedError(task *ecstypes.Task, target string, taskDef *ecstypes.TaskDefinition) error {
+	containers := containerResults(task, taskDef)
+	var targetResult *ContainerResult
+	for i := range containers {
+		if containers[i].Name == target {
+			targetResult = &containers[i]
+		}
+	}
+	if targetResult == nil {
+		return errors.Errorf("can not find target container %s in the task", target)
+	}
+
+	cause := stoppedBy(containers, target)
+	if cause == nil || cause.Name == target {
+		if targetResult.ExitCode != nil && targetResult.Reason == "" {
+			return errors.Errorf("exit code: %d", *targetResult.ExitCode)
+		}
+		if targetResult.ExitCode == nil && targetResult.Reason == "" && task.StoppedReason != nil {
+			return errors.Errorf("exit code: none, task stopped: %s", *task.StoppedReason)
+		}
+		return errors.New(targetResult.String())
+	}
+
+	others := []string{}
+	for _, c := range containers {
+		if c.Name != cause.Name && c.Name != target && c.failed() {
+			others = append(others, fmt.Sprintf("%s (%s)", c.Name, c.String()))
+		}
+	}
+	msg := fmt.Sprintf("essential container %s stopped the task (%s), target container %s (%s)", cause.Name, cause.String(), target, targetResult.String())
+	if len(others) > 0 {
+		msg += ", other containers " + strings.Join(others, ", ")
+	}
+	return errors.New(msg)
+}
+
+func exitCodeString(code *int32) string {
+	if code == nil {
+		return "none"
+	}
+	return fmt.Sprint(*code)
+}
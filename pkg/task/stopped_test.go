@@ -0,0 +1,130 @@
+package task
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	ecstypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+func TestStoppedError(t *testing.T) {
+	taskDef := &ecstypes.TaskDefinition{
+		ContainerDefinitions: []ecstypes.ContainerDefinition{
+			{Name: aws.String("target")},
+			{Name: aws.String("proxy"), Essential: aws.Bool(true)},
+			{Name: aws.String("log-router"), Essential: aws.Bool(false)},
+		},
+	}
+	cases := []struct {
+		name       string
+		containers []ecstypes.Container
+		expected   string
+	}{
+		{
+			name: "target failed",
+			containers: []ecstypes.Container{
+				{Name: aws.String("target"), ExitCode: aws.Int32(1)},
+				{Name: aws.String("proxy"), ExitCode: aws.Int32(143)},
+				{Name: aws.String("log-router"), ExitCode: aws.Int32(0)},
+			},
+			expected: "exit code: 1",
+		},
+		{
+			name: "sidecar OOM",
+			containers: []ecstypes.Container{
+				{Name: aws.String("target"), ExitCode: aws.Int32(137)},
+				{Name: aws.String("proxy"), ExitCode: aws.Int32(137), Reason: aws.String("OutOfMemoryError: Container killed due to memory usage")},
+				{Name: aws.String("log-router"), ExitCode: aws.Int32(0)},
+			},
+			expected: "essential container proxy stopped the task (exit code: 137, reason: OutOfMemoryError: Container killed due to memory usage), target container target (exit code: 137)",
+		},
+		{
+			name: "sidecar exited",
+			containers: []ecstypes.Container{
+				{Name: aws.String("target"), ExitCode: aws.Int32(143)},
+				{Name: aws.String("proxy"), ExitCode: aws.Int32(2)},
+				{Name: aws.String("log-router"), ExitCode: aws.Int32(1)},
+			},
+			expected: "essential container proxy stopped the task (exit code: 2), target container target (exit code: 143), other containers log-router (exit code: 1)",
+		},
+		{
+			name: "non-essential container failed",
+			containers: []ecstypes.Container{
+				{Name: aws.String("target"), ExitCode: aws.Int32(137)},
+				{Name: aws.String("proxy"), ExitCode: aws.Int32(0)},
+				{Name: aws.String("log-router"), ExitCode: aws.Int32(1)},
+			},
+			expected: "exit code: 137",
+		},
+		{
+			name: "target failed to start",
+			containers: []ecstypes.Container{
+				{Name: aws.String("target"), Reason: aws.String("CannotPullContainerError: pull image manifest has been retried 5 time(s)")},
+				{Name: aws.String("proxy")},
+			},
+			expected: "exit code: none, reason: CannotPullContainerError: pull image manifest has been retried 5 time(s)",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			task := &ecstypes.Task{
+				LastStatus:    aws.String("STOPPED"),
+				StoppedReason: aws.String("Essential container in task exited"),
+				Containers:    c.containers,
+			}
+			err := stoppedError(task, "target", taskDef)
+			if err == nil || err.Error() != c.expected {
+				t.Errorf("Error is not matched: %v", err)
+			}
+		})
+	}
+}
+
+func TestTaskResultCausedBy(t *testing.T) {
+	result := &TaskResult{}
+	result.setTask(&ecstypes.Task{
+		LastStatus: aws.String("STOPPED"),
+		StopCode:   ecstypes.TaskStopCodeEssentialContainerExited,
+		Containers: []ecstypes.Container{
+			{Name: aws.String("target"), ExitCode: aws.Int32(143)},
+			{Name: aws.String("proxy"), ExitCode: aws.Int32(137), Reason: aws.String("OutOfMemoryError")},
+		},
+	}, "target", nil)
+	if result.CausedBy != "proxy" {
+		t.Errorf("CausedBy is not matched: %s", result.CausedBy)
+	}
+	if result.StopCode != "EssentialContainerExited" {
+		t.Errorf("StopCode is not matched: %s", result.StopCode)
+	}
+	if len(result.Containers) != 2 || result.Containers[1].Reason != "OutOfMemoryError" || !result.Containers[1].Essential {
+		t.Errorf("Containers are not matched: %+v", result.Containers)
+	}
+}
+
+func TestWaitTaskWithoutExitCode(t *testing.T) {
+	describe := ecs.DescribeTasksOutput{
+		Tasks: []ecstypes.Task{
+			{
+				LastStatus:    aws.String("STOPPED"),
+				StopCode:      ecstypes.TaskStopCodeTaskFailedToStart,
+				StoppedReason: aws.String("CannotPullContainerError"),
+				Containers: []ecstypes.Container{
+					{Name: aws.String("target")},
+				},
+			},
+		},
+	}
+	task := &Task{
+		awsECS:    mockedWaitTask{Describe: describe},
+		Container: "target",
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := task.WaitTask(ctx, &ecstypes.Task{TaskArn: aws.String("test-arn")})
+	if err == nil || err.Error() != "exit code: none, task stopped: CannotPullContainerError" {
+		t.Errorf("Error is not matched: %v", err)
+	}
+}
@@ -33,6 +33,19 @@ For example:
 	// And wait to completion of task execution.
 	err = t.WaitTask(ctx, tasks)
 
+# Run many tasks with a Task
+
+A Task can be shared by many jobs. Clients are reused, and parameters of each job are provided to RunWith.
+Empty fields of RunInput are filled with fields of the Task. RunWith is safe for concurrent use,
+and the task is stopped when the context is canceled.
+
+For example:
+
+	result, err := t.RunWith(ctx, &task.RunInput{
+	    Cluster: "cluster-name",
+	    Command: []string{"bundle", "exec", "rake", "job"},
+	})
+
 # Polling CloudWatch Logs
 
 You can polling CloudWatch Logs log stream.
@@ -50,16 +63,40 @@ For example:
 	if err != nil {
 	    return err
 	}
+
+If you want to resume watching later, please persist w.Position() and provide it to NewWatcherFromPosition.
+
+# Notifications
+
+If you want to be notified when the task is started, succeeded or failed, please register a Notifier.
+Webhook, SlackNotifier, SNSNotifier and Pushgateway are provided, and you can implement your own Notifier.
+
+For example:
+
+	t, err := task.NewTask(..., task.WithNotifier(task.NewSlackNotifier("https://hooks.slack.com/services/...")))
+
+# Customize clients
+
+If you want to add middleware or a user agent to API calls, please provide options of clients.
+They are applied to every ECS and CloudWatch Logs client which Task creates.
+
+For example:
+
+	t, err := task.NewTask(..., task.WithECSOptions(func(o *ecs.Options) {
+	    o.APIOptions = append(o.APIOptions, middleware.AddUserAgentKeyValue("my-platform", "1.0"))
+	}))
 */
 package task
 
 import (
 	"context"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
 	ecstypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
@@ -75,9 +112,21 @@ type ECSClient interface {
 }
 
 // Task has target ECS information, client of aws-sdk-go, command and timeout seconds.
+//
+// A Task can run tasks repeatedly and concurrently. Clients are created in NewTask and reused by every run,
+// and state of each run is kept in the run, so please create a Task once and call RunWith for each job
+// with parameters of the job. Do not modify fields of the Task while it is running tasks.
 type Task struct {
 	awsECS  ECSClient
 	awsLogs *cloudwatchlogs.Client
+	// Config of CloudWatch Logs clients. Clients for other regions are created from this config.
+	logsConfig   *aws.Config
+	regionalLogs *logsClients
+	// Functions to customize clients, such as middleware and user agent.
+	ecsOptions  []func(*ecs.Options)
+	logsOptions []func(*cloudwatchlogs.Options)
+	// Functions to load the config, such as FIPS and dual-stack endpoints.
+	configOptions []func(*config.LoadOptions) error
 
 	// ECS Cluster where you want to run the task.
 	Cluster string
@@ -101,19 +150,128 @@ type Task struct {
 	PlatformVersion string
 	// If you don't enable this flag, the task access the internet throguth NAT gateway.
 	// Please read more information: https://docs.aws.amazon.com/AmazonECS/latest/developerguide/task-networking.html
-	AssignPublicIP  ecstypes.AssignPublicIp
+	AssignPublicIP ecstypes.AssignPublicIp
+	// Format of log messages which are JSON objects. Default is LogFormatRaw.
+	LogFormat LogFormat
+	// Fields which are extracted from JSON log messages with LogFormatFields.
+	LogFields []string
+	// If you set true, fields extracted from JSON log messages are colorized.
+	LogColor bool
+	// Writer which logs of the target container are written to. If nil, logs are written to stdout.
+	// Writes to writers are serialized line by line, so the same writer can be used by concurrent runs and containers.
+	LogWriter io.Writer
+	// If you want to get logs of other containers, or write logs of each container separately,
+	// please set writers for each container name.
+	ContainerWriters map[string]io.Writer
+	// Notifiers are notified when the task is started, succeeded or failed.
+	// They are called from each run concurrently, so they must be safe for concurrent use.
+	Notifiers       []Notifier
 	profile         string
 	region          string
 	timestampFormat string
 	// If you wat to override CPU and Memory, please set these values.
-	taskSizeCpu     string
-	taskSizeMemory  string
+	taskSizeCpu    string
+	taskSizeMemory string
+}
+
+// Option configures optional behaviors of Task in NewTask.
+type Option func(*Task)
+
+// WithNotifier registers the notifier which is notified when the task is started, succeeded or failed.
+func WithNotifier(n Notifier) Option {
+	return func(t *Task) {
+		t.Notifiers = append(t.Notifiers, n)
+	}
+}
+
+// WithLogsConfig uses the config to create CloudWatch Logs clients instead of the config for ECS.
+// It is useful when logs are stored in a centralized logging account.
+func WithLogsConfig(cfg aws.Config) Option {
+	return func(t *Task) {
+		t.logsConfig = &cfg
+	}
+}
+
+// WithConfigOptions applies the functions to load the config of AWS clients.
+// For example, config.WithUseFIPSEndpoint enables FIPS endpoints of all clients.
+// It does not affect the config which is provided to WithLogsConfig.
+func WithConfigOptions(fns ...func(*config.LoadOptions) error) Option {
+	return func(t *Task) {
+		t.configOptions = append(t.configOptions, fns...)
+	}
+}
+
+// WithECSOptions applies the functions to ECS client options, so they take effect on every ECS API call.
+// For example, you can add middleware to instrument API calls, or a user agent to attribute them.
+func WithECSOptions(fns ...func(*ecs.Options)) Option {
+	return func(t *Task) {
+		t.ecsOptions = append(t.ecsOptions, fns...)
+	}
+}
+
+// WithLogsOptions applies the functions to CloudWatch Logs client options, including clients for other regions.
+func WithLogsOptions(fns ...func(*cloudwatchlogs.Options)) Option {
+	return func(t *Task) {
+		t.logsOptions = append(t.logsOptions, fns...)
+	}
+}
+
+// RunInput has parameters of a run. Empty fields are filled with fields of Task.
+type RunInput struct {
+	// ECS Cluster where you want to run the task.
+	Cluster string
+	// Container name which you want to run.
+	Container string
+	// Name of Task Definition. You can provide full ARN, family or family:revision.
+	TaskDefinitionName string
+	// Command which you want to run.
+	Command []string
+	// If you wat to override CPU and Memory, please set these values.
+	TaskSizeCpu    string
+	TaskSizeMemory string
+	// Writer which logs of the target container are written to. It can be shared with other runs.
+	LogWriter io.Writer
+	// Writers for each container name. They can be shared with other runs and containers.
+	ContainerWriters map[string]io.Writer
+	// Prefix of each log line, such as "[cluster] ".
+	LogPrefix string
+}
+
+// resolve returns a copy of the input whose empty fields are filled with fields of the task.
+func (t *Task) resolve(input *RunInput) *RunInput {
+	in := RunInput{}
+	if input != nil {
+		in = *input
+	}
+	if in.Cluster == "" {
+		in.Cluster = t.Cluster
+	}
+	if in.Container == "" {
+		in.Container = t.Container
+	}
+	if in.TaskDefinitionName == "" {
+		in.TaskDefinitionName = t.TaskDefinitionName
+	}
+	if len(in.Command) == 0 {
+		in.Command = t.Command
+	}
+	if in.TaskSizeCpu == "" && in.TaskSizeMemory == "" {
+		in.TaskSizeCpu = t.taskSizeCpu
+		in.TaskSizeMemory = t.taskSizeMemory
+	}
+	if in.LogWriter == nil {
+		in.LogWriter = t.LogWriter
+	}
+	if in.ContainerWriters == nil {
+		in.ContainerWriters = t.ContainerWriters
+	}
+	return &in
 }
 
 // NewTask returns a new Task struct, and initialize aws ecs API client.
 // If you want to run the task as Fargate, please provide fargate flag to true, and your subnet IDs for awsvpc.
 // If you don't want to run the task as Fargate, please provide empty string for subnetIDs.
-func NewTask(cluster, container, taskDefinitionName, command string, fargate bool, subnetIDs, securityGroupIDs, platformVersion string, timeout time.Duration, timestampFormat, profile, region, taskSizeCpu, taskSizeMemory string) (*Task, error) {
+func NewTask(cluster, container, taskDefinitionName, command string, fargate bool, subnetIDs, securityGroupIDs, platformVersion string, timeout time.Duration, timestampFormat, profile, region, taskSizeCpu, taskSizeMemory string, opts ...Option) (*Task, error) {
 	if cluster == "" {
 		return nil, errors.New("Cluster name is required")
 	}
@@ -126,14 +284,6 @@ func NewTask(cluster, container, taskDefinitionName, command string, fargate boo
 	if command == "" {
 		return nil, errors.New("Command is required")
 	}
-	cfg, err := newConfig(profile, region)
-	if err != nil {
-		return nil, errors.Wrap(err, "Failed to create AWS Session")
-	}
-	awsECS := ecs.NewFromConfig(cfg)
-	awsLogs := cloudwatchlogs.NewFromConfig(cfg)
-
-	taskDefinition := NewTaskDefinition(awsECS)
 	p := shellwords.NewParser()
 	commands, err := p.Parse(command)
 	if err != nil {
@@ -158,13 +308,11 @@ func NewTask(cluster, container, taskDefinitionName, command string, fargate boo
 		}
 	}
 
-	return &Task{
-		awsECS:             awsECS,
-		awsLogs:            awsLogs,
+	t := &Task{
+		regionalLogs:       &logsClients{clients: map[string]*cloudwatchlogs.Client{}},
 		Cluster:            cluster,
 		Container:          container,
 		TaskDefinitionName: taskDefinitionName,
-		taskDefinition:     taskDefinition,
 		Command:            commands,
 		Timeout:            timeout,
 		LaunchType:         launchType,
@@ -177,14 +325,33 @@ func NewTask(cluster, container, taskDefinitionName, command string, fargate boo
 		PlatformVersion:    platformVersion,
 		taskSizeCpu:        taskSizeCpu,
 		taskSizeMemory:     taskSizeMemory,
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	cfg, err := NewConfig(profile, region, t.configOptions...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create AWS Session")
+	}
+	if t.logsConfig == nil {
+		t.logsConfig = &cfg
+	}
+	awsECS := ecs.NewFromConfig(cfg, t.ecsOptions...)
+	t.awsECS = awsECS
+	t.taskDefinition = NewTaskDefinition(awsECS)
+	t.awsLogs = cloudwatchlogs.NewFromConfig(*t.logsConfig, t.logsOptions...)
+	return t, nil
 }
 
 // RunTask calls run-task API. This function does not wait to completion of the task.
 func (t *Task) RunTask(ctx context.Context, taskDefinition *ecstypes.TaskDefinition) (*ecstypes.Task, error) {
+	return t.runTask(ctx, taskDefinition, t.resolve(nil))
+}
+
+func (t *Task) runTask(ctx context.Context, taskDefinition *ecstypes.TaskDefinition, in *RunInput) (*ecstypes.Task, error) {
 	containerOverride := ecstypes.ContainerOverride{
-		Command: t.Command,
-		Name:    aws.String(t.Container),
+		Command: in.Command,
+		Name:    aws.String(in.Container),
 	}
 
 	override := &ecstypes.TaskOverride{
@@ -193,9 +360,9 @@ func (t *Task) RunTask(ctx context.Context, taskDefinition *ecstypes.TaskDefinit
 		},
 	}
 
-	if len(t.taskSizeCpu) > 0 && len(t.taskSizeMemory) > 0 {
-		override.Cpu = aws.String(t.taskSizeCpu)
-		override.Memory = aws.String(t.taskSizeMemory)
+	if len(in.TaskSizeCpu) > 0 && len(in.TaskSizeMemory) > 0 {
+		override.Cpu = aws.String(in.TaskSizeCpu)
+		override.Memory = aws.String(in.TaskSizeMemory)
 	}
 
 	var params *ecs.RunTaskInput
@@ -210,7 +377,7 @@ func (t *Task) RunTask(ctx context.Context, taskDefinition *ecstypes.TaskDefinit
 		}
 		if len(t.PlatformVersion) > 0 {
 			params = &ecs.RunTaskInput{
-				Cluster:              aws.String(t.Cluster),
+				Cluster:              aws.String(in.Cluster),
 				TaskDefinition:       taskDefinition.TaskDefinitionArn,
 				Overrides:            override,
 				NetworkConfiguration: network,
@@ -219,7 +386,7 @@ func (t *Task) RunTask(ctx context.Context, taskDefinition *ecstypes.TaskDefinit
 			}
 		} else {
 			params = &ecs.RunTaskInput{
-				Cluster:              aws.String(t.Cluster),
+				Cluster:              aws.String(in.Cluster),
 				TaskDefinition:       taskDefinition.TaskDefinitionArn,
 				Overrides:            override,
 				NetworkConfiguration: network,
@@ -228,7 +395,7 @@ func (t *Task) RunTask(ctx context.Context, taskDefinition *ecstypes.TaskDefinit
 		}
 	} else {
 		params = &ecs.RunTaskInput{
-			Cluster:        aws.String(t.Cluster),
+			Cluster:        aws.String(in.Cluster),
 			TaskDefinition: taskDefinition.TaskDefinitionArn,
 			Overrides:      override,
 			LaunchType:     t.LaunchType,
@@ -244,7 +411,7 @@ func (t *Task) RunTask(ctx context.Context, taskDefinition *ecstypes.TaskDefinit
 		return nil, errors.New(*resp.Failures[0].Reason)
 	}
 	if len(resp.Tasks) == 1 {
-		log.Infof("Running tasks: %+v", resp.Tasks[0])
+		log.Infof("Running task: %s, command: %q", aws.ToString(resp.Tasks[0].TaskArn), RedactCommand(in.Command))
 		return &resp.Tasks[0], nil
 	} else {
 		return nil, errors.New(fmt.Sprintf("Expected ecs.RunTask with Count=nil to return exactly 1 task; received %d (%+v)", len(resp.Tasks), resp.Tasks))
@@ -253,33 +420,44 @@ func (t *Task) RunTask(ctx context.Context, taskDefinition *ecstypes.TaskDefinit
 
 // WaitTask waits completion of the task execition. If timeout occures, the function exits.
 func (t *Task) WaitTask(ctx context.Context, task *ecstypes.Task) error {
+	_, err := t.waitTask(ctx, task, t.resolve(nil), nil)
+	return err
+}
+
+// waitTask waits completion of the task execution, and returns the stopped task.
+// The task definition is used to know essential containers. If it is nil, all containers are regarded as essential.
+func (t *Task) waitTask(ctx context.Context, task *ecstypes.Task, in *RunInput, taskDef *ecstypes.TaskDefinition) (*ecstypes.Task, error) {
 	log.Info("Waiting for running task...")
-	err := t.waitExitTasks(ctx, *task.TaskArn)
+	stopped, err := t.waitExitTasks(ctx, *task.TaskArn, in, taskDef)
 	if err == context.DeadlineExceeded {
 		err = errors.New("process timeout")
 	}
 	if err == nil {
 		log.Info("Run task is success")
 	}
-	return err
+	return stopped, err
 }
 
-func (t *Task) waitExitTasks(ctx context.Context, taskArn string) error {
+// exitCodeRetries is the number of times to describe the stopped task again, when exit code of the target container is not set yet.
+const exitCodeRetries = 3
+
+func (t *Task) waitExitTasks(ctx context.Context, taskArn string, in *RunInput, taskDef *ecstypes.TaskDefinition) (*ecstypes.Task, error) {
+	missingExitCode := 0
 retry:
 	for {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return nil, ctx.Err()
 		case <-time.After(5 * time.Second):
 		}
 
 		params := &ecs.DescribeTasksInput{
-			Cluster: aws.String(t.Cluster),
+			Cluster: aws.String(in.Cluster),
 			Tasks:   []string{taskArn},
 		}
 		resp, err := t.awsECS.DescribeTasks(ctx, params)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		for _, task := range resp.Tasks {
@@ -288,17 +466,35 @@ retry:
 			}
 		}
 
+		var stopped *ecstypes.Task
 		for _, task := range resp.Tasks {
-			code, result, err := t.checkTaskSucceeded(task)
-			if err != nil {
+			stopped = &task
+			_, result, err := t.checkTaskSucceeded(task, in.Container)
+			if err != nil && missingExitCode < exitCodeRetries && !stoppedBeforeExit(task, in.Container) {
+				// Exit code may be set a little later than the task is stopped.
+				missingExitCode++
 				continue retry
 			}
-			if !result {
-				return errors.Errorf("exit code: %v", code)
+			if err != nil || !result {
+				return stopped, stoppedError(stopped, in.Container, taskDef)
 			}
 		}
-		return nil
+		return stopped, nil
+	}
+}
+
+// stoppedBeforeExit returns whether the target container will never have exit code,
+// because it failed to start or the task was stopped before it started.
+func stoppedBeforeExit(task ecstypes.Task, container string) bool {
+	if task.StopCode == ecstypes.TaskStopCodeTaskFailedToStart {
+		return true
+	}
+	for _, c := range task.Containers {
+		if c.Name != nil && *c.Name == container {
+			return c.Reason != nil
+		}
 	}
+	return false
 }
 
 func (t *Task) checkTaskStopped(task ecstypes.Task) bool {
@@ -308,10 +504,10 @@ func (t *Task) checkTaskStopped(task ecstypes.Task) bool {
 	return true
 }
 
-func (t *Task) checkTaskSucceeded(task ecstypes.Task) (int32, bool, error) {
+func (t *Task) checkTaskSucceeded(task ecstypes.Task, container string) (int32, bool, error) {
 	var targetContainer *ecstypes.Container
 	for _, c := range task.Containers {
-		if *c.Name == t.Container {
+		if *c.Name == container {
 			targetContainer = &c
 		}
 	}
@@ -2,6 +2,7 @@ package task
 
 import (
 	"context"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
@@ -11,6 +12,9 @@ import (
 
 type TaskDefinitionClient interface {
 	DescribeTaskDefinition(ctx context.Context, params *ecs.DescribeTaskDefinitionInput, optFns ...func(*ecs.Options)) (*ecs.DescribeTaskDefinitionOutput, error)
+	RegisterTaskDefinition(ctx context.Context, params *ecs.RegisterTaskDefinitionInput, optFns ...func(*ecs.Options)) (*ecs.RegisterTaskDefinitionOutput, error)
+	ListTaskDefinitionFamilies(ctx context.Context, params *ecs.ListTaskDefinitionFamiliesInput, optFns ...func(*ecs.Options)) (*ecs.ListTaskDefinitionFamiliesOutput, error)
+	ListTaskDefinitions(ctx context.Context, params *ecs.ListTaskDefinitionsInput, optFns ...func(*ecs.Options)) (*ecs.ListTaskDefinitionsOutput, error)
 }
 
 // TaskDefinition has client of aws-sdk-go.
@@ -41,8 +45,89 @@ func (d *TaskDefinition) DescribeTaskDefinition(ctx context.Context, taskDefinit
 	return resp.TaskDefinition, nil
 }
 
+// RegisterTaskDefinition registers a new task definition, and returns it.
+func (d *TaskDefinition) RegisterTaskDefinition(ctx context.Context, params *ecs.RegisterTaskDefinitionInput) (*ecstypes.TaskDefinition, error) {
+	resp, err := d.awsECS.RegisterTaskDefinition(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return resp.TaskDefinition, nil
+}
+
+// ListFamilies returns active task definition families which start with the prefix.
+func (d *TaskDefinition) ListFamilies(ctx context.Context, prefix string) ([]string, error) {
+	params := &ecs.ListTaskDefinitionFamiliesInput{
+		Status: ecstypes.TaskDefinitionFamilyStatusActive,
+	}
+	if prefix != "" {
+		params.FamilyPrefix = aws.String(prefix)
+	}
+	families := []string{}
+	paginator := ecs.NewListTaskDefinitionFamiliesPaginator(d.awsECS, params)
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		families = append(families, resp.Families...)
+	}
+	return families, nil
+}
+
+// ListRevisions returns active revisions of the family as family:revision, newest first.
+func (d *TaskDefinition) ListRevisions(ctx context.Context, family string) ([]string, error) {
+	params := &ecs.ListTaskDefinitionsInput{
+		FamilyPrefix: aws.String(family),
+		Status:       ecstypes.TaskDefinitionStatusActive,
+		Sort:         ecstypes.SortOrderDesc,
+	}
+	revisions := []string{}
+	paginator := ecs.NewListTaskDefinitionsPaginator(d.awsECS, params)
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, arn := range resp.TaskDefinitionArns {
+			// Task definition ARN format is `arn:aws:ecs:<region>:<aws_account_id>:task-definition/<family>:<revision>`.
+			revision := arn[strings.LastIndex(arn, "/")+1:]
+			// FamilyPrefix matches other families which start with the family.
+			if strings.HasPrefix(revision, family+":") {
+				revisions = append(revisions, revision)
+			}
+		}
+	}
+	return revisions, nil
+}
+
+// ContainerNames returns names of containers in the task definition.
+func (d *TaskDefinition) ContainerNames(taskDef *ecstypes.TaskDefinition) []string {
+	names := []string{}
+	for _, c := range taskDef.ContainerDefinitions {
+		names = append(names, aws.ToString(c.Name))
+	}
+	return names
+}
+
+// LogConfiguration has awslogs options of a container.
+type LogConfiguration struct {
+	Group        string
+	StreamPrefix string
+	// Region where the log group is. If it is empty, the log group is in the same region as the task.
+	Region string
+}
+
 // GetLogGroup gets cloudwatch logs group and stream prefix.
 func (d *TaskDefinition) GetLogGroup(taskDef *ecstypes.TaskDefinition, containerName string) (string, string, error) {
+	c, err := d.GetLogConfiguration(taskDef, containerName)
+	if err != nil {
+		return "", "", err
+	}
+	return c.Group, c.StreamPrefix, nil
+}
+
+// GetLogConfiguration gets cloudwatch logs group, stream prefix and region.
+func (d *TaskDefinition) GetLogConfiguration(taskDef *ecstypes.TaskDefinition, containerName string) (*LogConfiguration, error) {
 	var containerDefinition *ecstypes.ContainerDefinition
 
 	for _, c := range taskDef.ContainerDefinitions {
@@ -51,13 +136,24 @@ func (d *TaskDefinition) GetLogGroup(taskDef *ecstypes.TaskDefinition, container
 		}
 	}
 	if containerDefinition == nil {
-		return "", "", errors.New("Cannot find container")
+		return nil, errors.New("Cannot find container")
 	}
-	if containerDefinition.LogConfiguration.LogDriver != ecstypes.LogDriverAwslogs {
-		return "", "", errors.New("Log driver is not awslogs")
+	if containerDefinition.LogConfiguration == nil || containerDefinition.LogConfiguration.LogDriver != ecstypes.LogDriverAwslogs {
+		return nil, errors.New("Log driver is not awslogs")
 	}
 	logDriver := containerDefinition.LogConfiguration.Options
-	group := logDriver["awslogs-group"]
-	streamPrefix := logDriver["awslogs-stream-prefix"]
-	return group, streamPrefix, nil
+	return &LogConfiguration{
+		Group:        logDriver["awslogs-group"],
+		StreamPrefix: logDriver["awslogs-stream-prefix"],
+		Region:       logDriver["awslogs-region"],
+	}, nil
+}
+
+// Family returns the family of the task definition, which is provided as full ARN, family or family:revision.
+func Family(taskDefinition string) string {
+	if i := strings.LastIndex(taskDefinition, "task-definition/"); i >= 0 {
+		taskDefinition = taskDefinition[i+len("task-definition/"):]
+	}
+	family, _, _ := strings.Cut(taskDefinition, ":")
+	return family
 }
@@ -18,6 +18,26 @@ func (m mockedDescribeTaskDefinition) DescribeTaskDefinition(ctx context.Context
 	return &m.Resp, nil
 }
 
+type mockedListFamilies struct {
+	TaskDefinitionClient
+	Resp  ecs.ListTaskDefinitionFamiliesOutput
+	input *ecs.ListTaskDefinitionFamiliesInput
+}
+
+func (m *mockedListFamilies) ListTaskDefinitionFamilies(ctx context.Context, params *ecs.ListTaskDefinitionFamiliesInput, optFns ...func(*ecs.Options)) (*ecs.ListTaskDefinitionFamiliesOutput, error) {
+	m.input = params
+	return &m.Resp, nil
+}
+
+type mockedListRevisions struct {
+	TaskDefinitionClient
+	Resp ecs.ListTaskDefinitionsOutput
+}
+
+func (m mockedListRevisions) ListTaskDefinitions(ctx context.Context, params *ecs.ListTaskDefinitionsInput, optFns ...func(*ecs.Options)) (*ecs.ListTaskDefinitionsOutput, error) {
+	return &m.Resp, nil
+}
+
 func TestDescribeTaskDefinition(t *testing.T) {
 	resp := ecs.DescribeTaskDefinitionOutput{
 		TaskDefinition: &ecstypes.TaskDefinition{
@@ -67,3 +87,92 @@ func TestGetLogGroup(t *testing.T) {
 		t.Error("Stream prefix is invalid")
 	}
 }
+
+func TestGetLogConfiguration(t *testing.T) {
+	taskDef := &ecstypes.TaskDefinition{
+		ContainerDefinitions: []ecstypes.ContainerDefinition{
+			{
+				Name: aws.String("TaskContainer"),
+				LogConfiguration: &ecstypes.LogConfiguration{
+					LogDriver: ecstypes.LogDriverAwslogs,
+					Options: map[string]string{
+						"awslogs-group":         "GroupName",
+						"awslogs-stream-prefix": "LogPrefix",
+						"awslogs-region":        "us-east-1",
+					},
+				},
+			},
+			{
+				Name: aws.String("NoLogContainer"),
+			},
+		},
+	}
+	taskDefinition := &TaskDefinition{}
+	c, err := taskDefinition.GetLogConfiguration(taskDef, "TaskContainer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Group != "GroupName" || c.StreamPrefix != "LogPrefix" || c.Region != "us-east-1" {
+		t.Errorf("Log configuration is invalid: %+v", c)
+	}
+
+	_, err = taskDefinition.GetLogConfiguration(taskDef, "NoLogContainer")
+	if err == nil {
+		t.Error("Does not error when container does not have log configuration")
+	}
+}
+
+func TestListFamilies(t *testing.T) {
+	client := &mockedListFamilies{
+		Resp: ecs.ListTaskDefinitionFamiliesOutput{
+			Families: []string{"web", "worker"},
+		},
+	}
+	taskDefinition := &TaskDefinition{
+		awsECS: client,
+	}
+	families, err := taskDefinition.ListFamilies(context.Background(), "w")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *client.input.FamilyPrefix != "w" {
+		t.Error("Family prefix is invalid")
+	}
+	if len(families) != 2 || families[0] != "web" {
+		t.Errorf("Families are invalid: %v", families)
+	}
+}
+
+func TestContainerNames(t *testing.T) {
+	taskDef := &ecstypes.TaskDefinition{
+		ContainerDefinitions: []ecstypes.ContainerDefinition{
+			{Name: aws.String("app")},
+			{Name: aws.String("sidecar")},
+		},
+	}
+	names := (&TaskDefinition{}).ContainerNames(taskDef)
+	if len(names) != 2 || names[0] != "app" || names[1] != "sidecar" {
+		t.Errorf("Container names are invalid: %v", names)
+	}
+}
+
+func TestListRevisions(t *testing.T) {
+	taskDefinition := &TaskDefinition{
+		awsECS: mockedListRevisions{
+			Resp: ecs.ListTaskDefinitionsOutput{
+				TaskDefinitionArns: []string{
+					"arn:aws:ecs:ap-northeast-1:1234567890:task-definition/web:2",
+					"arn:aws:ecs:ap-northeast-1:1234567890:task-definition/web-worker:5",
+					"arn:aws:ecs:ap-northeast-1:1234567890:task-definition/web:1",
+				},
+			},
+		},
+	}
+	revisions, err := taskDefinition.ListRevisions(context.Background(), "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 2 || revisions[0] != "web:2" || revisions[1] != "web:1" {
+		t.Errorf("Revisions are invalid: %v", revisions)
+	}
+}
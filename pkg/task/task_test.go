@@ -2,10 +2,13 @@ package task
 
 import (
 	"context"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
 	ecstypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
 )
@@ -21,6 +24,21 @@ type mockedWaitTask struct {
 	Describe ecs.DescribeTasksOutput
 }
 
+type mockedRecordRunTask struct {
+	ECSClient
+	mu     sync.Mutex
+	params []*ecs.RunTaskInput
+}
+
+func (m *mockedRecordRunTask) RunTask(ctx context.Context, params *ecs.RunTaskInput, opts ...func(*ecs.Options)) (*ecs.RunTaskOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.params = append(m.params, params)
+	return &ecs.RunTaskOutput{
+		Tasks: []ecstypes.Task{{TaskArn: aws.String("arn:aws:ecs:ap-northeast-1:123456789012:task/" + *params.Cluster + "/0123")}},
+	}, nil
+}
+
 func (m mockedRunTask) RunTask(ctx context.Context, params *ecs.RunTaskInput, opts ...func(*ecs.Options)) (*ecs.RunTaskOutput, error) {
 	return &m.Run, nil
 }
@@ -117,3 +135,114 @@ func TestWaitTask(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestRunTaskWithInput(t *testing.T) {
+	awsECS := &mockedRecordRunTask{}
+	task := &Task{
+		awsECS:         awsECS,
+		Cluster:        "default",
+		Container:      "app",
+		Command:        []string{"echo", "default"},
+		LaunchType:     ecstypes.LaunchTypeEc2,
+		taskSizeCpu:    "256",
+		taskSizeMemory: "512",
+	}
+	taskDef := &ecstypes.TaskDefinition{
+		TaskDefinitionArn: aws.String("task-definition-arn"),
+	}
+	inputs := []*RunInput{
+		nil,
+		{Cluster: "staging", Command: []string{"echo", "staging"}},
+		{Cluster: "production", Container: "worker", TaskSizeCpu: "1024", TaskSizeMemory: "2048"},
+	}
+	var wg sync.WaitGroup
+	for _, input := range inputs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := task.runTask(context.Background(), taskDef, task.resolve(input)); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	params := map[string]*ecs.RunTaskInput{}
+	for _, p := range awsECS.params {
+		params[*p.Cluster] = p
+	}
+	cases := []struct {
+		cluster   string
+		container string
+		command   string
+		cpu       string
+	}{
+		{"default", "app", "echo default", "256"},
+		{"staging", "app", "echo staging", "256"},
+		{"production", "worker", "echo default", "1024"},
+	}
+	for _, c := range cases {
+		p, ok := params[c.cluster]
+		if !ok {
+			t.Errorf("Task is not run on %s", c.cluster)
+			continue
+		}
+		override := p.Overrides.ContainerOverrides[0]
+		if *override.Name != c.container {
+			t.Errorf("Container on %s is not matched: %s", c.cluster, *override.Name)
+		}
+		if strings.Join(override.Command, " ") != c.command {
+			t.Errorf("Command on %s is not matched: %v", c.cluster, override.Command)
+		}
+		if *p.Overrides.Cpu != c.cpu {
+			t.Errorf("Cpu on %s is not matched: %s", c.cluster, *p.Overrides.Cpu)
+		}
+	}
+	if task.Cluster != "default" || task.Container != "app" {
+		t.Errorf("Task is modified: %+v", task)
+	}
+}
+
+func TestNewTaskWithClientOptions(t *testing.T) {
+	task, err := NewTask("cluster", "app", "family", "echo", false, "", "", "", 0, "", "", "ap-northeast-1", "", "",
+		WithECSOptions(func(o *ecs.Options) {
+			o.AppID = "ecs-app"
+		}),
+		WithLogsOptions(func(o *cloudwatchlogs.Options) {
+			o.AppID = "logs-app"
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if appID := task.awsECS.(*ecs.Client).Options().AppID; appID != "ecs-app" {
+		t.Errorf("ECS options are not applied: %s", appID)
+	}
+	for _, region := range []string{"ap-northeast-1", "us-east-1"} {
+		options := task.logsClient(region).Options()
+		if options.AppID != "logs-app" || options.Region != region {
+			t.Errorf("CloudWatch Logs options are not applied for %s: %s, %s", region, options.AppID, options.Region)
+		}
+	}
+}
+
+func TestNewTaskWithEndpointOptions(t *testing.T) {
+	task, err := NewTask("cluster", "app", "family", "echo", false, "", "", "", 0, "", "", "us-gov-west-1", "", "",
+		WithConfigOptions(EndpointOptions(true, true)...),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecsOptions := task.awsECS.(*ecs.Client).Options().EndpointOptions
+	if ecsOptions.UseFIPSEndpoint != aws.FIPSEndpointStateEnabled || ecsOptions.UseDualStackEndpoint != aws.DualStackEndpointStateEnabled {
+		t.Errorf("Endpoint options of ECS are invalid: %+v", ecsOptions)
+	}
+	logsOptions := task.logsClient("us-gov-east-1").Options().EndpointOptions
+	if logsOptions.UseFIPSEndpoint != aws.FIPSEndpointStateEnabled || logsOptions.UseDualStackEndpoint != aws.DualStackEndpointStateEnabled {
+		t.Errorf("Endpoint options of CloudWatch Logs are invalid: %+v", logsOptions)
+	}
+
+	if fns := EndpointOptions(false, false); len(fns) != 0 {
+		t.Errorf("Endpoint options are provided without flags: %d", len(fns))
+	}
+}
@@ -1,8 +1,14 @@
 package task
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -17,12 +23,72 @@ type LogsClient interface {
 	GetLogEvents(ctx context.Context, params *cloudwatchlogs.GetLogEventsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.GetLogEventsOutput, error)
 }
 
+// LogFormat is a format to print log messages which are JSON objects.
+type LogFormat string
+
+const (
+	// LogFormatRaw prints log messages as they are.
+	LogFormatRaw LogFormat = "raw"
+	// LogFormatFields extracts selected fields from JSON log messages, and colorizes them.
+	LogFormatFields LogFormat = "fields"
+	// LogFormatPretty indents JSON log messages.
+	LogFormatPretty LogFormat = "pretty"
+)
+
+// DefaultLogFields are fields which are extracted from JSON log messages with LogFormatFields.
+var DefaultLogFields = []string{"level", "msg", "error"}
+
+// writeMu serializes writes of all watchers, because they can share a writer which is not safe for concurrent use.
+var writeMu sync.Mutex
+
+// ParseLogFormat returns a LogFormat according to the name.
+func ParseLogFormat(name string) (LogFormat, error) {
+	switch f := LogFormat(name); f {
+	case "", LogFormatRaw:
+		return LogFormatRaw, nil
+	case LogFormatFields, LogFormatPretty:
+		return f, nil
+	default:
+		return "", errors.Errorf("Unknown log format: %s", name)
+	}
+}
+
 // Watcher has log group information and CloudWatchLogs Client.
 type Watcher struct {
 	awsLogs         LogsClient
 	Group           string
 	Stream          string
 	timestampFormat string
+	// Format of log messages which are JSON objects. Default is LogFormatRaw.
+	Format LogFormat
+	// Fields which are extracted with LogFormatFields. If empty, DefaultLogFields is used.
+	Fields []string
+	// If you set true, extracted fields are colorized.
+	Color bool
+	// Prefix of each output line.
+	Prefix string
+	// Writer which logs are written to. If nil, logs are written to stdout.
+	// Writes of all watchers are serialized line by line, so watchers can share a writer.
+	Writer io.Writer
+
+	mu       sync.Mutex
+	position Position
+	// Number of events which are skipped when resuming with the timestamp.
+	skip int
+}
+
+// Position is a position of Watcher in the log stream.
+// It can be persisted as JSON, and Watcher resumes watching from the position with NewWatcherFromPosition.
+type Position struct {
+	Group string `json:"group"`
+	// Full name of the log stream.
+	Stream string `json:"stream"`
+	// Forward token of GetLogEvents. It is used to resume at first.
+	NextForwardToken string `json:"nextForwardToken,omitempty"`
+	// Timestamp of the last printed event in milliseconds. It is used to resume when the token is expired.
+	LastTimestamp int64 `json:"lastTimestamp,omitempty"`
+	// Number of printed events which have LastTimestamp. They are skipped when resuming with LastTimestamp.
+	LastTimestampCount int `json:"lastTimestampCount,omitempty"`
 }
 
 // NewWatcher returns a Watcher struct.
@@ -35,6 +101,21 @@ func NewWatcher(group, stream string, awsLogs *cloudwatchlogs.Client, timestampF
 	}
 }
 
+// NewWatcherFromPosition returns a Watcher struct which resumes watching from the position.
+// Events which were printed before the position are not printed again.
+func NewWatcherFromPosition(position Position, awsLogs *cloudwatchlogs.Client, timestampFormat string) *Watcher {
+	w := NewWatcher(position.Group, position.Stream, awsLogs, timestampFormat)
+	w.position = position
+	return w
+}
+
+// Position returns the current position of the Watcher. It is safe to call while polling.
+func (w *Watcher) Position() Position {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.position
+}
+
 // GetStreams get cloudwatch logs streams according to log group name and stream prefix.
 func (w *Watcher) GetStreams(ctx context.Context) ([]logstypes.LogStream, error) {
 	input := &cloudwatchlogs.DescribeLogStreamsInput{
@@ -86,8 +167,8 @@ func (w *Watcher) Polling(ctx context.Context) error {
 		return err
 	}
 	log.Infof("Log Stream: %+v", stream)
-	fmt.Printf("Watching log stream: %s\n", *stream.Arn)
-	var nextToken *string
+	w.printf("%sWatching log stream: %s\n", w.Prefix, *stream.Arn)
+	nextToken, startTime := w.resume(*stream.LogStreamName)
 	for {
 		select {
 		case <-time.After(2 * time.Second):
@@ -97,14 +178,22 @@ func (w *Watcher) Polling(ctx context.Context) error {
 				LogStreamName: stream.LogStreamName,
 				StartFromHead: aws.Bool(true),
 				NextToken:     nextToken,
+				StartTime:     startTime,
 			}
 			output, err := w.awsLogs.GetLogEvents(ctx, input)
 			if err != nil {
+				var invalid *logstypes.InvalidParameterException
+				if nextToken != nil && startTime == nil && errors.As(err, &invalid) {
+					log.Warnf("Failed to resume with the token, so resume with the timestamp: %v", err)
+					nextToken, startTime = nil, w.lastTimestamp()
+					continue
+				}
 				return err
 			}
 			// Update next token
 			nextToken = output.NextForwardToken
-			w.printEvents(output.Events)
+			startTime = nil
+			w.printEvents(w.advance(output.Events, nextToken))
 		case <-ctx.Done():
 			log.Info("WaitStream: exiting loop due to Context done")
 			// discard ctx.Err(); it is normal to be Canceled
@@ -113,16 +202,193 @@ func (w *Watcher) Polling(ctx context.Context) error {
 	}
 }
 
+// resume returns the token and the start time to resume watching the stream.
+func (w *Watcher) resume(stream string) (*string, *int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.position.Stream != stream {
+		w.position = Position{Group: w.Group, Stream: stream}
+		return nil, nil
+	}
+	if w.position.NextForwardToken != "" {
+		return aws.String(w.position.NextForwardToken), nil
+	}
+	return nil, w.resumeTimestamp()
+}
+
+func (w *Watcher) lastTimestamp() *int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.resumeTimestamp()
+}
+
+// resumeTimestamp returns the last timestamp, and skips events which were printed at the timestamp.
+func (w *Watcher) resumeTimestamp() *int64 {
+	if w.position.LastTimestamp == 0 {
+		return nil
+	}
+	w.skip = w.position.LastTimestampCount
+	return aws.Int64(w.position.LastTimestamp)
+}
+
+// advance updates the position with the events and the token, and returns events which are not printed yet.
+func (w *Watcher) advance(events []logstypes.OutputLogEvent, nextToken *string) []logstypes.OutputLogEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	unprinted := []logstypes.OutputLogEvent{}
+	for _, event := range events {
+		timestamp := aws.ToInt64(event.Timestamp)
+		if w.skip > 0 && timestamp == w.position.LastTimestamp {
+			w.skip--
+			continue
+		}
+		w.skip = 0
+		if timestamp == w.position.LastTimestamp {
+			w.position.LastTimestampCount++
+		} else {
+			w.position.LastTimestamp = timestamp
+			w.position.LastTimestampCount = 1
+		}
+		unprinted = append(unprinted, event)
+	}
+	w.position.NextForwardToken = aws.ToString(nextToken)
+	return unprinted
+}
+
 func (w *Watcher) printEvents(events []logstypes.OutputLogEvent) {
 	for _, event := range events {
 		// AWS returns milliseconds of unix time.
 		// So we have to transfer to second, nanoseconds.
 		timestamp := time.Unix(*event.Timestamp/1000, *event.Timestamp%1000*1000000)
-		message := *event.Message
+		message := w.formatMessage(*event.Message)
 		sTimestamp := timestamp.Format(w.timestampFormat)
 		if sTimestamp != "" {
 			sTimestamp += " "
 		}
-		fmt.Printf("%s%s\n", sTimestamp, message)
+		w.printf("%s%s%s\n", w.Prefix, sTimestamp, message)
+	}
+}
+
+// printf writes a line to the writer. Lines are not interleaved even if the writer is shared.
+func (w *Watcher) printf(format string, a ...interface{}) {
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	fmt.Fprintf(w.writer(), format, a...)
+}
+
+func (w *Watcher) writer() io.Writer {
+	if w.Writer == nil {
+		return os.Stdout
+	}
+	return w.Writer
+}
+
+// formatMessage formats the message according to Format.
+// When the message is not a JSON object, it is returned as it is.
+func (w *Watcher) formatMessage(message string) string {
+	if w.Format == "" || w.Format == LogFormatRaw {
+		return message
+	}
+	trimmed := strings.TrimSpace(message)
+	if !strings.HasPrefix(trimmed, "{") {
+		return message
+	}
+	switch w.Format {
+	case LogFormatPretty:
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, []byte(trimmed), "", "  "); err != nil {
+			return message
+		}
+		return buf.String()
+	case LogFormatFields:
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(trimmed), &object); err != nil {
+			return message
+		}
+		return w.formatFields(object, message)
+	}
+	return message
+}
+
+func (w *Watcher) formatFields(object map[string]json.RawMessage, original string) string {
+	fields := w.Fields
+	if len(fields) == 0 {
+		fields = DefaultLogFields
+	}
+
+	level := ""
+	for _, key := range []string{"level", "severity", "lvl"} {
+		if raw, ok := object[key]; ok && contains(fields, key) {
+			level = strings.ToLower(rawString(raw))
+			break
+		}
+	}
+	color := levelColor(level)
+
+	parts := []string{}
+	for _, key := range fields {
+		raw, ok := object[key]
+		if !ok {
+			continue
+		}
+		value := rawString(raw)
+		switch key {
+		case "level", "severity", "lvl":
+			parts = append(parts, w.colorize(color, strings.ToUpper(value)))
+		case "msg", "message":
+			parts = append(parts, value)
+		default:
+			if isRawString(raw) {
+				value = fmt.Sprintf("%q", value)
+			}
+			parts = append(parts, w.colorize(color, key)+"="+value)
+		}
+	}
+	if len(parts) == 0 {
+		return original
+	}
+	return strings.Join(parts, " ")
+}
+
+func (w *Watcher) colorize(color int, text string) string {
+	if !w.Color {
+		return text
+	}
+	return fmt.Sprintf("\x1b[%dm%s\x1b[0m", color, text)
+}
+
+// levelColor returns an ANSI color code in the same manner as logrus.
+func levelColor(level string) int {
+	switch level {
+	case "trace", "debug":
+		return 37
+	case "warn", "warning":
+		return 33
+	case "error", "fatal", "panic", "critical":
+		return 31
+	default:
+		return 36
+	}
+}
+
+// rawString returns the string value of a JSON string, otherwise the raw JSON.
+func rawString(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return string(raw)
+}
+
+func isRawString(raw json.RawMessage) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(raw), []byte(`"`))
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
 	}
+	return false
 }
@@ -1,7 +1,10 @@
 package task
 
 import (
+	"bytes"
 	"context"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -63,3 +66,159 @@ func TestWaitStream(t *testing.T) {
 		t.Error("Does not error when multiple streams")
 	}
 }
+
+func TestFormatMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   LogFormat
+		fields   []string
+		color    bool
+		message  string
+		expected string
+	}{
+		{
+			name:     "Raw",
+			format:   LogFormatRaw,
+			message:  `{"level":"info","msg":"hello"}`,
+			expected: `{"level":"info","msg":"hello"}`,
+		},
+		{
+			name:     "NotJSON",
+			format:   LogFormatFields,
+			message:  "plain text",
+			expected: "plain text",
+		},
+		{
+			name:     "Fields",
+			format:   LogFormatFields,
+			message:  `{"time":"2006-01-02","level":"error","msg":"failed","error":"boom","code":1}`,
+			expected: `ERROR failed error="boom"`,
+		},
+		{
+			name:     "SelectedFields",
+			format:   LogFormatFields,
+			fields:   []string{"msg", "code"},
+			message:  `{"level":"error","msg":"failed","code":1}`,
+			expected: `failed code=1`,
+		},
+		{
+			name:     "NoMatchedFields",
+			format:   LogFormatFields,
+			message:  `{"foo":"bar"}`,
+			expected: `{"foo":"bar"}`,
+		},
+		{
+			name:     "Color",
+			format:   LogFormatFields,
+			color:    true,
+			message:  `{"level":"warn","msg":"careful"}`,
+			expected: "\x1b[33mWARN\x1b[0m careful",
+		},
+		{
+			name:     "Pretty",
+			format:   LogFormatPretty,
+			message:  `{"b":1,"a":{"c":"d"}}`,
+			expected: "{\n  \"b\": 1,\n  \"a\": {\n    \"c\": \"d\"\n  }\n}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &Watcher{
+				Format: tt.format,
+				Fields: tt.fields,
+				Color:  tt.color,
+			}
+			message := w.formatMessage(tt.message)
+			if message != tt.expected {
+				t.Errorf("Message is invalid: %q", message)
+			}
+		})
+	}
+}
+
+func TestPrintEventsWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := &Watcher{
+		Prefix: "[cluster] ",
+		Writer: &buf,
+	}
+	w.printEvents([]logstypes.OutputLogEvent{
+		{
+			Timestamp: aws.Int64(0),
+			Message:   aws.String("hoge"),
+		},
+	})
+	if buf.String() != "[cluster] hoge\n" {
+		t.Errorf("Output is invalid: %q", buf.String())
+	}
+}
+
+func TestPrintEventsSharedWriter(t *testing.T) {
+	var buf bytes.Buffer
+	var wg sync.WaitGroup
+	for _, prefix := range []string{"[a] ", "[b] "} {
+		w := &Watcher{Prefix: prefix, Writer: &buf}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				w.printEvents([]logstypes.OutputLogEvent{{Timestamp: aws.Int64(0), Message: aws.String("hoge")}})
+			}
+		}()
+	}
+	wg.Wait()
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 200 {
+		t.Fatalf("Number of lines is invalid: %d", len(lines))
+	}
+	for _, line := range lines {
+		if line != "[a] hoge" && line != "[b] hoge" {
+			t.Errorf("Line is invalid: %q", line)
+		}
+	}
+}
+
+func TestWatcherPosition(t *testing.T) {
+	event := func(timestamp int64, message string) logstypes.OutputLogEvent {
+		return logstypes.OutputLogEvent{Timestamp: aws.Int64(timestamp), Message: aws.String(message)}
+	}
+
+	w := NewWatcher("Group", "Stream", nil, "")
+	token, startTime := w.resume("Stream/task-id")
+	if token != nil || startTime != nil {
+		t.Error("New watcher resumes from somewhere")
+	}
+	printed := w.advance([]logstypes.OutputLogEvent{event(1, "a"), event(2, "b"), event(2, "c")}, aws.String("token"))
+	if len(printed) != 3 {
+		t.Errorf("Printed events are invalid: %v", printed)
+	}
+	position := w.Position()
+	expected := Position{Group: "Group", Stream: "Stream/task-id", NextForwardToken: "token", LastTimestamp: 2, LastTimestampCount: 2}
+	if position != expected {
+		t.Errorf("Position is invalid: %+v", position)
+	}
+
+	// Resume with the token.
+	resumed := NewWatcherFromPosition(position, nil, "")
+	token, startTime = resumed.resume("Stream/task-id")
+	if aws.ToString(token) != "token" || startTime != nil {
+		t.Errorf("Watcher does not resume with the token: %v, %v", token, startTime)
+	}
+	printed = resumed.advance([]logstypes.OutputLogEvent{event(2, "d")}, aws.String("token2"))
+	if len(printed) != 1 || resumed.Position().LastTimestampCount != 3 {
+		t.Errorf("Events after the token are invalid: %v", printed)
+	}
+
+	// Resume with the timestamp when the token is not available.
+	position.NextForwardToken = ""
+	resumed = NewWatcherFromPosition(position, nil, "")
+	token, startTime = resumed.resume("Stream/task-id")
+	if token != nil || aws.ToInt64(startTime) != 2 {
+		t.Errorf("Watcher does not resume with the timestamp: %v, %v", token, startTime)
+	}
+	printed = resumed.advance([]logstypes.OutputLogEvent{event(2, "b"), event(2, "c"), event(2, "d"), event(3, "e")}, aws.String("token3"))
+	if len(printed) != 2 || *printed[0].Message != "d" || *printed[1].Message != "e" {
+		t.Errorf("Printed events after resuming are invalid: %v", printed)
+	}
+}
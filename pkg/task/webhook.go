@@ -0,0 +1,95 @@
+package task
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// WebhookSignatureHeader is a header which has HMAC-SHA256 signature of the payload, like `sha256=<hex digest>`.
+const WebhookSignatureHeader = "X-Ecs-Task-Signature"
+
+// Webhook posts TaskResult as JSON to the URL.
+type Webhook struct {
+	URL string
+	// If you set Secret, the payload is signed with HMAC-SHA256.
+	Secret string
+	client *http.Client
+}
+
+// WebhookPayload is a body which is posted to the webhook.
+type WebhookPayload struct {
+	Event  Event       `json:"event"`
+	Result *TaskResult `json:"result"`
+}
+
+// NewWebhook returns a new Webhook struct.
+// If secret is empty, ECS_TASK_WEBHOOK_SECRET environment variable is used.
+func NewWebhook(url, secret string) *Webhook {
+	return &Webhook{
+		URL:    url,
+		Secret: getenv(secret, "ECS_TASK_WEBHOOK_SECRET"),
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Post posts the event and the result to the webhook.
+func (w *Webhook) Post(ctx context.Context, event Event, result *TaskResult) error {
+	body, err := json.Marshal(&WebhookPayload{
+		Event:  event,
+		Result: result,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, "sha256="+w.sign(body))
+	}
+	client := w.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("Webhook returns status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// OnStart posts EventStart.
+func (w *Webhook) OnStart(ctx context.Context, result *TaskResult) error {
+	return w.Post(ctx, EventStart, result)
+}
+
+// OnSuccess posts EventSuccess.
+func (w *Webhook) OnSuccess(ctx context.Context, result *TaskResult) error {
+	return w.Post(ctx, EventSuccess, result)
+}
+
+// OnFailure posts EventFailure.
+func (w *Webhook) OnFailure(ctx context.Context, result *TaskResult) error {
+	return w.Post(ctx, EventFailure, result)
+}
+
+// sign returns hex encoded HMAC-SHA256 of the body.
+func (w *Webhook) sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(w.Secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,59 @@
+package task
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookPost(t *testing.T) {
+	var body []byte
+	var signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(WebhookSignatureHeader)
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(server.URL, "secret")
+	err := webhook.Post(context.Background(), EventFailure, &TaskResult{Cluster: "cluster", Error: "exit code: 1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var payload WebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Event != EventFailure {
+		t.Errorf("Event is invalid: %s", payload.Event)
+	}
+	if payload.Result.Cluster != "cluster" || payload.Result.Error != "exit code: 1" {
+		t.Errorf("Result is invalid: %+v", payload.Result)
+	}
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	if signature != "sha256="+hex.EncodeToString(mac.Sum(nil)) {
+		t.Errorf("Signature is invalid: %s", signature)
+	}
+}
+
+func TestWebhookPostError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(server.URL, "")
+	err := webhook.Post(context.Background(), EventStart, &TaskResult{})
+	if err == nil {
+		t.Error("Does not error when webhook returns 500")
+	}
+}